@@ -0,0 +1,580 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+var (
+	pass = client.Cell{Result: client.CellResultPass}
+	fail = client.Cell{Result: client.CellResultFail, Message: "timed out"}
+	skip = client.Cell{Result: client.CellResultSkipped}
+	none = client.Cell{}
+)
+
+// fakeAPI serves a dashboard with three tabs, one of which cannot be fetched
+type fakeAPI struct {
+	rows map[string][]client.Row
+}
+
+func (f fakeAPI) ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error) {
+	return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "gce"}, {Name: "broken"}, {Name: "kind"}}}, nil
+}
+
+func (f fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	rows, ok := f.rows[tab]
+	if !ok {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	return &client.RowsResponse{Rows: rows}, nil
+}
+
+func (f fakeAPI) GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error) {
+	return &client.HeadersResponse{Headers: []client.Header{{Build: "2"}, {Build: "1"}}}, nil
+}
+
+var matrixAPI = fakeAPI{rows: map[string][]client.Row{
+	"gce": {
+		{Name: "Overall", Cells: []client.Cell{fail, fail}},
+		{Name: "everywhere", Cells: []client.Cell{none, fail, pass, pass, pass}},
+		{Name: "gce-only", Cells: []client.Cell{fail, fail}},
+		{Name: "healthy", Cells: []client.Cell{pass, pass}},
+	},
+	"kind": {
+		{Name: "everywhere", Cells: []client.Cell{fail, fail}},
+		{Name: "gce-only", Cells: []client.Cell{pass, fail}},
+		{Name: "kind-only", Cells: []client.Cell{skip, pass}},
+	},
+}}
+
+func TestFetchDashboardRows(t *testing.T) {
+	tabs, errs, err := FetchDashboardRows(context.Background(), matrixAPI, "d", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tabs) != 2 || tabs[0].Tab != "gce" || tabs[1].Tab != "kind" {
+		t.Errorf("expected tabs in dashboard order, got %+v", tabs)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 tab error, got %v", errs)
+	}
+
+	grids, errs, err := FetchDashboardGrids(context.Background(), matrixAPI, "d", 2)
+	if err != nil || len(errs) != 1 {
+		t.Fatalf("unexpected errors: %v, %v", err, errs)
+	}
+	if len(grids) != 2 || grids[1].Tab != "kind" || len(grids[1].Headers) != 2 || len(grids[1].Rows) != 3 {
+		t.Errorf("unexpected grids: %+v", grids)
+	}
+}
+
+func TestBuildMatrix(t *testing.T) {
+	tabs, _, _ := FetchDashboardRows(context.Background(), matrixAPI, "d", 2)
+	m := BuildMatrix("d", tabs, 3)
+
+	if len(m.Rows) != 4 {
+		t.Fatalf("expected 4 tests (Overall excluded), got %d", len(m.Rows))
+	}
+	order := []string{"everywhere", "gce-only", "healthy", "kind-only"}
+	for i, name := range order {
+		if m.Rows[i].Test != name {
+			t.Errorf("row %d: expected %s, got %s", i, name, m.Rows[i].Test)
+		}
+	}
+
+	everywhere := m.Rows[0]
+	if everywhere.FailingTabs != 2 {
+		t.Errorf("expected everywhere to fail in 2 tabs, got %d", everywhere.FailingTabs)
+	}
+	if c := everywhere.Cells[0]; c.Latest != "FAIL" || c.Runs != 3 || c.Passes != 2 || c.Message != "timed out" {
+		t.Errorf("unexpected gce cell: %+v", c)
+	}
+
+	healthy := m.Rows[2]
+	if healthy.Cells[1] != nil {
+		t.Errorf("expected no kind cell for healthy, got %+v", healthy.Cells[1])
+	}
+	if healthy.Cells[0].PassRate != 1 {
+		t.Errorf("expected pass rate 1, got %v", healthy.Cells[0].PassRate)
+	}
+
+	if c := m.Rows[3].Cells[1]; c.Latest != "SKIPPED" || c.Runs != 1 {
+		t.Errorf("unexpected kind-only cell: %+v", c)
+	}
+
+	filtered := m.FilterMinFailing(2)
+	if len(filtered.Rows) != 1 || len(m.Rows) != 4 {
+		t.Errorf("expected filter to keep 1 row without modifying the matrix, got %d and %d", len(filtered.Rows), len(m.Rows))
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	headers := []client.Header{
+		{Build: "105", Extra: []string{"abc123"}},
+		{Build: "104"},
+		{Build: "103"},
+		{Build: "102"},
+		{Build: "101"},
+	}
+	row := client.Row{Name: "test", Cells: []client.Cell{fail, none, fail, skip, pass, fail}}
+
+	h := BuildHistory("d", "tab", headers, row, nil)
+	if len(h.Runs) != 5 {
+		t.Fatalf("expected 5 runs (empty cell skipped), got %+v", h.Runs)
+	}
+	if r := h.Runs[0]; r.Build != "105" || r.Commit != "abc123" || r.Status != "FAIL" || r.Message != "timed out" {
+		t.Errorf("unexpected first run: %+v", r)
+	}
+	if r := h.Runs[4]; r.Build != "" || r.Status != "FAIL" {
+		t.Errorf("expected a run without header past the last column, got %+v", r)
+	}
+
+	s := h.Stats
+	if s.Runs != 5 || s.Passes != 1 || s.Failures != 3 || s.Skipped != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.Consecutive != 2 || s.Flips != 2 || s.PassRate != 0.25 {
+		t.Errorf("unexpected streak, flips or pass rate: %+v", s)
+	}
+	if s.LastPass != "101" || s.LastFailure != "105" {
+		t.Errorf("unexpected last pass or failure: %+v", s)
+	}
+}
+
+func TestFindRow(t *testing.T) {
+	rows := []client.Row{{Name: "Overall"}, {Name: "[sig-node] Pods should run"}, {Name: "[sig-node] Pods should stop"}}
+
+	r, err := FindRow(rows, "[sig-node] pods should run")
+	if err != nil || r.Name != "[sig-node] Pods should run" {
+		t.Errorf("expected case-insensitive match, got %v, %v", r, err)
+	}
+
+	var resolveErr *client.ResolveError
+	if _, err := FindRow(rows, "[sig-node] Pods"); !errors.As(err, &resolveErr) || !resolveErr.Ambiguous {
+		t.Errorf("expected an ambiguous match, got %v", err)
+	}
+}
+
+func TestGroupBySIG(t *testing.T) {
+	tabs := []TabRows{
+		{Tab: "gce", Rows: []client.Row{
+			{Name: "Overall", Cells: []client.Cell{fail}},
+			{Name: "[sig-node] Pods", Cells: []client.Cell{fail, pass}},
+			{Name: "[sig-node] Lifecycle", Cells: []client.Cell{pass, fail}},
+			{Name: "[sig-storage] CSI", Cells: []client.Cell{pass, pass}},
+		}},
+		{Tab: "kind", Rows: []client.Row{
+			{Name: "[sig-node] Pods", Cells: []client.Cell{pass}},
+			{Name: "unit", Cells: []client.Cell{none}},
+		}},
+	}
+
+	got := GroupBySIG(tabs, 10)
+	if len(got) != 3 {
+		t.Fatalf("expected node, none and storage, got %+v", got)
+	}
+	node := got[0]
+	if node.SIG != "node" || node.Tests != 3 || node.Failing != 1 || node.Flaky != 1 || node.Runs != 5 || node.Passes != 3 {
+		t.Errorf("unexpected node stats: %+v", node)
+	}
+	if got[1].SIG != "none" || got[1].PassRate != -1 {
+		t.Errorf("expected untagged tests without runs second, got %+v", got[1])
+	}
+	if got[2].SIG != "storage" || got[2].PassRate != 1 {
+		t.Errorf("unexpected storage stats: %+v", got[2])
+	}
+	if len(node.Offenders) != 2 || node.Offenders[0].Test != "[sig-node] Pods" || !node.Offenders[0].Failing || node.Offenders[0].Consecutive != 1 {
+		t.Errorf("expected the failing test as first offender, got %+v", node.Offenders)
+	}
+
+	// Untagged tests belong to the dashboard's SIG, and skips do not end a
+	// run of failures, so a skip after a failure is still failing
+	tabs = []TabRows{{Dashboard: "sig-node-release-blocking", Tab: "gce", Rows: []client.Row{
+		{Name: "node-e2e", Cells: []client.Cell{skip, fail, pass}},
+	}}}
+	got = GroupBySIG(tabs, 10)
+	if len(got) != 1 || got[0].SIG != "node" || got[0].Failing != 1 || got[0].Flaky != 0 || got[0].Dashboards != 1 || got[0].FailingTabs != 1 {
+		t.Errorf("unexpected stats for untagged tests: %+v", got)
+	}
+	if o := got[0].Offenders; len(o) != 1 || !o[0].Failing || o[0].Consecutive != 1 {
+		t.Errorf("expected a failing offender with 1 consecutive failure, got %+v", o)
+	}
+}
+
+func TestDetectInfraFailures(t *testing.T) {
+	// Column 1 fails almost everything, column 2 never got to run tests and
+	// column 3 is a single real failure
+	rows := []client.Row{{Name: "Overall", Cells: []client.Cell{pass, fail, fail, fail}}}
+	for i := range 12 {
+		cells := []client.Cell{pass, fail, none, pass}
+		if i == 0 {
+			cells[1] = pass
+			cells[3] = fail
+		}
+		rows = append(rows, client.Row{Name: fmt.Sprintf("test-%d", i), Cells: cells})
+	}
+	headers := []client.Header{{Build: "104"}, {Build: "103"}, {Build: "102"}}
+
+	cols := DetectInfraFailures(headers, rows, DefaultInfraOptions)
+	if len(cols) != 2 {
+		t.Fatalf("expected columns 1 and 2, got %+v", cols)
+	}
+	if c := cols[0]; c.Column != 1 || c.Build != "103" || c.Failed != 11 || c.Ran != 12 || c.Reason != "11 of 12 tests failed" {
+		t.Errorf("unexpected failed column: %+v", c)
+	}
+	if c := cols[1]; c.Column != 2 || c.Ran != 0 || c.Reason != "build failed with 12 of 12 tests not run" {
+		t.Errorf("unexpected empty column: %+v", c)
+	}
+
+	if small := DetectInfraFailures(nil, rows[:3], DefaultInfraOptions); len(small) != 1 || small[0].Column != 2 {
+		t.Errorf("expected failure ratio not to apply below MinTests, got %+v", small)
+	}
+
+	masked := MaskColumns(rows, cols)
+	if masked[1].Cells[1] != none || rows[1].Cells[1] != pass {
+		t.Errorf("expected masked copy without modifying rows")
+	}
+	if c := SummarizeCells(ExcludeInfraFailures(rows)[2].Cells, 0); c.Runs != 2 || c.Passes != 2 {
+		t.Errorf("expected infra failures to be excluded from pass rates, got %+v", c)
+	}
+}
+
+func TestBuildHistoryInfra(t *testing.T) {
+	row := client.Row{Name: "test", Cells: []client.Cell{pass, fail, pass}}
+	h := BuildHistory("d", "tab", nil, row, []InfraColumn{{Column: 1}})
+	if len(h.Runs) != 3 || !h.Runs[1].Infra || h.Runs[0].Infra {
+		t.Fatalf("expected the middle run to be marked, got %+v", h.Runs)
+	}
+	if s := h.Stats; s.Runs != 2 || s.Failures != 0 || s.Flips != 0 || s.LastFailure != "" {
+		t.Errorf("expected infra run to be left out of stats, got %+v", s)
+	}
+}
+
+func TestAnalyzeBuilds(t *testing.T) {
+	headers := []client.Header{
+		{Build: "103", Started: "2026-01-28T12:00:00Z", Extra: []string{"abc"}},
+		{Build: "102", Started: "2026-01-28T10:00:00Z"},
+		{Build: "101", Started: "2026-01-28T09:00:00Z"},
+	}
+	rows := []client.Row{
+		{Name: "Overall", Cells: []client.Cell{fail, pass}},
+		{Name: "a", Cells: []client.Cell{fail, pass, pass}},
+		{Name: "b", Cells: []client.Cell{pass, skip, none}},
+	}
+	now := time.Date(2026, 1, 28, 12, 30, 0, 0, time.UTC)
+
+	got := AnalyzeBuilds(headers, rows, now)
+	if len(got.Builds) != 3 {
+		t.Fatalf("expected 3 builds, got %+v", got.Builds)
+	}
+	if b := got.Builds[0]; b.Build != "103" || b.Commit != "abc" || b.Pass != 1 || b.Fail != 1 || b.Green || b.Gap != "2h0m0s" {
+		t.Errorf("unexpected latest build: %+v", b)
+	}
+	if b := got.Builds[1]; b.Pass != 1 || b.Skip != 1 || !b.Green {
+		t.Errorf("unexpected green build: %+v", b)
+	}
+	// Without an Overall result a build is green if tests ran and none failed
+	if b := got.Builds[2]; b.Pass != 1 || b.Empty != 1 || !b.Green || b.Gap != "" {
+		t.Errorf("unexpected oldest build: %+v", b)
+	}
+	if got.LastGreen != "102" || got.SinceLast != "30m0s" || got.MedianGap != "2h0m0s" {
+		t.Errorf("unexpected summary: last green %s, since last %s, median gap %s", got.LastGreen, got.SinceLast, got.MedianGap)
+	}
+}
+
+// staleAPI serves a dashboard with an hourly tab that stopped three hours
+// ago, a daily tab on schedule and a tab with too few builds
+type staleAPI struct{}
+
+func (staleAPI) ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error) {
+	if dashboard == "broken" {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "hourly"}, {Name: "daily"}, {Name: "new"}}}, nil
+}
+
+func (staleAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{{TabName: "hourly", OverallStatus: "PASSING"}}}, nil
+}
+
+func (staleAPI) GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error) {
+	var last time.Time
+	var period time.Duration
+	n := 6
+	switch tab {
+	case "hourly":
+		last, period = staleNow.Add(-3*time.Hour-30*time.Minute), time.Hour
+	case "daily":
+		last, period = staleNow.Add(-12*time.Hour), 24*time.Hour
+	default:
+		last, period, n = staleNow, time.Hour, 2
+	}
+	resp := &client.HeadersResponse{}
+	for i := range n {
+		resp.Headers = append(resp.Headers, client.Header{Build: fmt.Sprint(i), Started: last.Add(-time.Duration(i) * period).Format(time.RFC3339)})
+	}
+	return resp, nil
+}
+
+var staleNow = time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+
+func TestInferCadence(t *testing.T) {
+	resp, _ := staleAPI{}.GetTabHeaders(context.Background(), "d", "hourly")
+	// A retried run and a header without a start time do not change the period
+	headers := append(resp.Headers, client.Header{Build: "retry", Started: resp.Headers[1].Started}, client.Header{Build: "pending"})
+
+	c, ok := InferCadence(headers)
+	if !ok || c.Period != time.Hour || !c.LastRun.Equal(staleNow.Add(-210*time.Minute)) {
+		t.Fatalf("unexpected cadence: %+v, %v", c, ok)
+	}
+	if got := c.Overdue(staleNow); got != 3.5 {
+		t.Errorf("expected 3.5 periods overdue, got %v", got)
+	}
+
+	if _, ok := InferCadence(headers[:3]); ok {
+		t.Error("expected no cadence from two intervals")
+	}
+}
+
+func TestFindStale(t *testing.T) {
+	res := FindStale(context.Background(), staleAPI{}, []string{"d", "broken"}, StaleOptions{Concurrency: 2, Now: staleNow})
+	if res.Checked != 2 || res.Uninferred != 1 || len(res.Errors) != 1 {
+		t.Errorf("unexpected counts: %+v", res)
+	}
+	if len(res.Tabs) != 1 {
+		t.Fatalf("expected only the hourly tab to be overdue, got %+v", res.Tabs)
+	}
+	if s := res.Tabs[0]; s.Tab != "hourly" || s.Period != "1h0m0s" || s.Since != "3h30m0s" || s.Status != "PASSING" {
+		t.Errorf("unexpected stale tab: %+v", s)
+	}
+
+	res = FindStale(context.Background(), staleAPI{}, []string{"d"}, StaleOptions{Multiple: 4, All: true, Now: staleNow})
+	if len(res.Tabs) != 2 || res.Tabs[0].Tab != "hourly" || res.Tabs[1].Tab != "daily" {
+		t.Errorf("expected every inferred tab, most overdue first, got %+v", res.Tabs)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	// Oldest to newest: "broken" fails three times in a row and recovers,
+	// "flaky" fails once and "latest" fails in the last two builds, after a
+	// day without runs
+	headers := []client.Header{
+		{Build: "8", Started: "2026-01-28T08:00:00Z"},
+		{Build: "7", Started: "2026-01-28T07:00:00Z"},
+		{Build: "6", Started: "2026-01-28T06:00:00Z"},
+		{Build: "5", Started: "2026-01-28T05:00:00Z"},
+		{Build: "4", Started: "2026-01-27T04:00:00Z"},
+		{Build: "3", Started: "2026-01-27T03:00:00Z"},
+	}
+	rows := []client.Row{
+		{Name: "broken", Cells: []client.Cell{pass, pass, pass, fail, fail, fail}},
+		{Name: "flaky", Cells: []client.Cell{pass, pass, fail, pass, pass, pass}},
+		{Name: "latest", Cells: []client.Cell{fail, fail, pass, none, pass, pass}},
+	}
+	now := time.Date(2026, 1, 28, 9, 0, 0, 0, time.UTC)
+
+	sim := Simulate(headers, rows, DefaultStatusOptions, now)
+	if sim.Status != "FLAKY" || len(sim.Alerting) != 0 {
+		t.Errorf("expected FLAKY without alerts, got %s (%s) alerting %v", sim.Status, sim.Reason, sim.Alerting)
+	}
+	statuses := make([]string, len(sim.Columns))
+	for i, c := range sim.Columns {
+		statuses[i] = c.Status
+	}
+	if want := []string{"FLAKY", "FLAKY", "FLAKY", "FAILING", "FLAKY", "FLAKY"}; !slices.Equal(statuses, want) {
+		t.Errorf("unexpected column statuses: got %v, want %v", statuses, want)
+	}
+
+	var kinds []string
+	for _, e := range sim.Events {
+		kinds = append(kinds, e.Kind+" "+e.Build+" "+e.Test)
+	}
+	if want := []string{"resolved 6 broken", "fired 5 broken", "stale 5 "}; !slices.Equal(kinds, want) {
+		t.Errorf("unexpected events: got %q, want %q", kinds, want)
+	}
+
+	opts := DefaultStatusOptions
+	opts.FailuresToAlert = 2
+	if sim := Simulate(headers, rows, opts, now); sim.Status != "FAILING" || !slices.Equal(sim.Alerting, []string{"latest"}) {
+		t.Errorf("expected latest to alert after 2 failures, got %s alerting %v", sim.Status, sim.Alerting)
+	}
+
+	if sim := Simulate(headers, rows, DefaultStatusOptions, now.Add(48*time.Hour)); sim.Status != "STALE" {
+		t.Errorf("expected STALE two days after the last run, got %s", sim.Status)
+	}
+
+	opts = DefaultStatusOptions
+	opts.BrokenThreshold = 0.5
+	if sim := Simulate(headers, rows, opts, now); sim.Status != "FLAKY" {
+		t.Errorf("expected 1 of 3 failing tests to stay below the broken threshold, got %s", sim.Status)
+	}
+}
+
+func TestDetectChurn(t *testing.T) {
+	headers := []client.Header{
+		{Build: "4", Started: "2026-01-28T00:00:00Z"},
+		{Build: "3", Started: "2026-01-27T00:00:00Z"},
+		{Build: "pending"},
+		{Build: "2", Started: "2026-01-20T00:00:00Z"},
+		{Build: "1", Started: "2026-01-19T00:00:00Z"},
+	}
+	rows := []client.Row{
+		{Name: "Overall", Cells: []client.Cell{pass, pass, none, pass, pass}},
+		{Name: "stable", Cells: []client.Cell{pass, pass, none, pass, pass}},
+		{Name: "[sig-node] Pods should be deleted", Cells: []client.Cell{none, none, none, pass, fail}},
+		{Name: "[sig-node] Pod should be deleted [Conformance]", Cells: []client.Cell{pass, fail}},
+		{Name: "[sig-node] Pods should be evicted", Cells: []client.Cell{none, none, none, pass, pass}},
+		{Name: "[sig-node] Pods should be evicted on pressure", Cells: []client.Cell{fail, fail}},
+		{Name: "brand new", Cells: []client.Cell{skip}},
+		{Name: "only pending", Cells: []client.Cell{none, none, pass}},
+	}
+	since := time.Date(2026, 1, 21, 0, 0, 0, 0, time.UTC)
+
+	c, err := DetectChurn(headers, rows, ChurnOptions{Since: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Baseline != 2 || c.Recent != 2 || c.Tests != 3 {
+		t.Errorf("expected 2 baseline and 2 recent builds with 3 tests, got %d, %d and %d", c.Baseline, c.Recent, c.Tests)
+	}
+	names := func(tests []ChurnTest) []string {
+		var out []string
+		for _, t := range tests {
+			out = append(out, t.Name)
+		}
+		return out
+	}
+	// The evicted test's results changed, so it is not taken for a rename
+	if got, want := names(c.Removed), []string{"[sig-node] Pods should be evicted"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected removed tests: got %q, want %q", got, want)
+	}
+	if got, want := names(c.Added), []string{"[sig-node] Pods should be evicted on pressure", "brand new"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected added tests: got %q, want %q", got, want)
+	}
+	if len(c.Renamed) != 1 || c.Renamed[0].From.Name != "[sig-node] Pods should be deleted" ||
+		c.Renamed[0].From.LastRun != "2" || c.Renamed[0].To.FirstRun != "3" {
+		t.Errorf("expected the deleted test to be renamed, got %+v", c.Renamed)
+	}
+
+	if _, err := DetectChurn(headers, rows, ChurnOptions{Since: since.AddDate(0, 0, -7)}); err == nil {
+		t.Error("expected an error without baseline builds")
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"[sig-node] Pods should run", "[sig-apps] Pods should run [Serial]", 1, 1},
+		{"Pods should run", "pods should-run", 1, 1},
+		{"Pods should be deleted", "Pods should be deleted gracefully", 0.6, 0.7},
+		{"abc", "xyz", 0, 0},
+	} {
+		if s := NameSimilarity(tt.a, tt.b); s < tt.min || s > tt.max {
+			t.Errorf("NameSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tt.a, tt.b, s, tt.min, tt.max)
+		}
+	}
+}
+
+func TestAuditSkipped(t *testing.T) {
+	unsupported := client.Cell{Result: client.CellResultSkipped, Message: "Only supported for providers [gce]\nat e2e.go:12"}
+	tabs := []TabRows{
+		{Tab: "gce", Rows: []client.Row{
+			{Name: "Overall", Cells: []client.Cell{skip, skip}},
+			{Name: "[sig-storage] provider specific", Cells: []client.Cell{unsupported, none, unsupported}},
+			{Name: "untagged", Cells: []client.Cell{skip, skip}},
+			{Name: "ran before the window", Cells: []client.Cell{skip, skip, pass}},
+			{Name: "runs", Cells: []client.Cell{skip, pass}},
+			{Name: "never ran", Cells: []client.Cell{none, none}},
+		}},
+		{Tab: "kind", Rows: []client.Row{
+			{Name: "[sig-storage] provider specific", Cells: []client.Cell{unsupported}},
+		}},
+	}
+
+	audit := AuditSkipped("blocking", tabs, 2)
+	if audit.Tests != 6 || audit.Skipped != 4 {
+		t.Errorf("expected 4 of 6 tests skipped, got %d of %d", audit.Skipped, audit.Tests)
+	}
+	if want := []TabSkips{{"gce", 5, 3}, {"kind", 1, 1}}; !slices.Equal(audit.Tabs, want) {
+		t.Errorf("unexpected tab totals: got %+v, want %+v", audit.Tabs, want)
+	}
+	if len(audit.Reasons) != 2 {
+		t.Fatalf("expected 2 reasons, got %+v", audit.Reasons)
+	}
+	// Reasons with as many tests are ordered by name
+	if r := audit.Reasons[1]; r.Reason != "Only supported for providers [gce]" || len(r.Tests) != 2 ||
+		r.Tests[0].Tab != "gce" || r.Tests[0].SIG != "storage" || r.Tests[0].Skips != 1 {
+		t.Errorf("unexpected provider reason: %+v", r)
+	}
+	if r := audit.Reasons[0]; r.Reason != NoSkipReason || len(r.Tests) != 2 || r.Tests[0].Test != "ran before the window" {
+		t.Errorf("unexpected reason without message: %+v", r)
+	}
+
+	if audit := AuditSkipped("blocking", tabs, 0); audit.Skipped != 3 {
+		t.Errorf("expected 3 tests skipped over every column, got %d", audit.Skipped)
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	commits := func(started ...string) []client.Header {
+		var out []client.Header
+		for i, s := range started {
+			out = append(out, client.Header{Build: fmt.Sprint(len(started) - i), Started: s, Extra: []string{fmt.Sprintf("c%d", len(started)-i)}})
+		}
+		return out
+	}
+	tabs := []TabGrid{
+		{Tab: "gce", Headers: commits("2026-01-28T03:00:00Z", "2026-01-28T02:00:00Z", "2026-01-28T01:00:00Z"), Rows: []client.Row{
+			{Name: "Overall", Cells: []client.Cell{fail, fail, fail}},
+			{Name: "regression", Cells: []client.Cell{fail, pass, pass}},
+			{Name: "environment", Cells: []client.Cell{pass, fail, fail}},
+		}},
+		{Tab: "kind", Headers: commits("2026-01-28T03:30:00Z", "2026-01-28T02:30:00Z", "2026-01-28T01:30:00Z"), Rows: []client.Row{
+			{Name: "regression", Cells: []client.Cell{fail, pass, skip}},
+			{Name: "environment", Cells: []client.Cell{pass, pass, pass}},
+		}},
+		// Without commits, columns are aligned by time only
+		{Tab: "aws", Headers: []client.Header{{Build: "a", Started: "2026-01-28T05:10:00Z"}, {Build: "pending"}}, Rows: []client.Row{
+			{Name: "regression", Cells: []client.Cell{fail, fail}},
+		}},
+	}
+
+	c := Correlate("blocking", tabs, CorrelateOptions{})
+	if c.Shared != 3 {
+		t.Errorf("expected 3 shared commits, got %d", c.Shared)
+	}
+	if len(c.Tests) != 2 {
+		t.Fatalf("expected 2 failing tests, got %+v", c.Tests)
+	}
+	r := c.Tests[0]
+	if r.Test != "regression" || r.Label != Widespread || r.Widespread != 1 || r.Unaligned != 1 || r.CoFailureRate != 1 ||
+		!slices.Equal(r.Tabs, []string{"aws", "gce", "kind"}) {
+		t.Errorf("unexpected regression correlation: %+v", r)
+	}
+	// The aws failure ran later than every commit, so it comes first
+	if len(r.Events) != 2 || r.Events[0].By != "time" || r.Events[1].Key != "c3" || !slices.Equal(r.Events[1].Failed, []string{"gce", "kind"}) {
+		t.Errorf("unexpected regression events: %+v", r.Events)
+	}
+	e := c.Tests[1]
+	if e.Test != "environment" || e.Label != Localized || e.Localized != 2 || e.CoFailureRate != 0.5 || !slices.Equal(e.Tabs, []string{"gce"}) {
+		t.Errorf("unexpected environment correlation: %+v", e)
+	}
+
+	// By time, the hourly columns of gce and kind share buckets and aws does not
+	c = Correlate("blocking", tabs, CorrelateOptions{ByTime: true, TimeWindow: time.Hour})
+	if c.Shared != 3 || c.Tests[0].Widespread != 1 {
+		t.Errorf("expected the same result aligned by time, got %d shared and %+v", c.Shared, c.Tests[0])
+	}
+	if c = Correlate("blocking", tabs, CorrelateOptions{Builds: 1}); c.Tests[0].Failures != 2 || len(c.Tests) != 1 {
+		t.Errorf("expected only the latest columns to count, got %+v", c.Tests)
+	}
+}
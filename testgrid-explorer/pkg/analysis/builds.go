@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// BuildStats are the results of one build column
+type BuildStats struct {
+	Column  int    `json:"column"`
+	Build   string `json:"build"`
+	Started string `json:"started,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Pass    int    `json:"pass"`
+	Fail    int    `json:"fail"`
+	Skip    int    `json:"skip"`
+	Empty   int    `json:"empty"`
+	// Gap is the time since the previous (older) build started
+	Gap string `json:"gap,omitempty"`
+	// Green is set when the Overall row passed, or without an Overall row
+	// when tests ran and none failed
+	Green bool `json:"green"`
+	// Infra is the reason the column looks like an infra failure, if it does
+	Infra string `json:"infra,omitempty"`
+}
+
+// TabBuilds are the per-build statistics of a tab, most recent first
+type TabBuilds struct {
+	Builds []BuildStats `json:"builds"`
+	// LastGreen is the most recent green build
+	LastGreen string `json:"last_green,omitempty"`
+	// MedianGap is the typical time between builds
+	MedianGap string `json:"median_gap,omitempty"`
+	// SinceLast is the time since the most recent build started
+	SinceLast string `json:"since_last,omitempty"`
+}
+
+// AnalyzeBuilds counts the results of every column of a tab
+//
+// Durations are rounded to the minute and formatted like "1h30m0s".
+func AnalyzeBuilds(headers []client.Header, rows []client.Row, now time.Time) *TabBuilds {
+	out := &TabBuilds{Builds: make([]BuildStats, len(headers))}
+	for i, h := range headers {
+		b := &out.Builds[i]
+		b.Column = i
+		b.Build = h.Build
+		b.Started = h.Started
+		if len(h.Extra) > 0 {
+			b.Commit = strings.TrimSpace(h.Extra[0])
+		}
+	}
+
+	overall := -1
+	for ri, r := range rows {
+		if r.Name == client.OverallRow {
+			overall = ri
+			continue
+		}
+		for i := range out.Builds {
+			result := client.CellResultEmpty
+			if i < len(r.Cells) {
+				result = r.Cells[i].Result
+			}
+			b := &out.Builds[i]
+			switch result {
+			case client.CellResultPass:
+				b.Pass++
+			case client.CellResultFail:
+				b.Fail++
+			case client.CellResultEmpty:
+				b.Empty++
+			default:
+				b.Skip++
+			}
+		}
+	}
+
+	for _, ic := range DetectInfraFailures(headers, rows, DefaultInfraOptions) {
+		if ic.Column < len(out.Builds) {
+			out.Builds[ic.Column].Infra = ic.Reason
+		}
+	}
+
+	var gaps []time.Duration
+	for i := range out.Builds {
+		b := &out.Builds[i]
+		if overall >= 0 && i < len(rows[overall].Cells) && rows[overall].Cells[i].Result != client.CellResultEmpty {
+			b.Green = rows[overall].Cells[i].Result == client.CellResultPass
+		} else {
+			b.Green = b.Pass > 0 && b.Fail == 0
+		}
+		if b.Green && out.LastGreen == "" {
+			out.LastGreen = b.Build
+		}
+
+		started, err := client.ParseTimestamp(b.Started)
+		if err != nil {
+			continue
+		}
+		if i == 0 {
+			out.SinceLast = roundDuration(now.Sub(started))
+		}
+		if i+1 < len(out.Builds) {
+			if prev, err := client.ParseTimestamp(out.Builds[i+1].Started); err == nil {
+				gap := started.Sub(prev)
+				b.Gap = roundDuration(gap)
+				gaps = append(gaps, gap)
+			}
+		}
+	}
+	if len(gaps) > 0 {
+		slices.Sort(gaps)
+		out.MedianGap = roundDuration(gaps[len(gaps)/2])
+	}
+	return out
+}
+
+func roundDuration(d time.Duration) string {
+	return d.Round(time.Minute).String()
+}
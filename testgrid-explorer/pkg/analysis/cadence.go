@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// Defaults for StaleOptions
+const (
+	DefaultOverdueMultiple = 3.0
+	// minIntervals is the number of intervals between builds needed to
+	// infer a cadence
+	minIntervals = 3
+)
+
+// Cadence is a tab's inferred run schedule
+type Cadence struct {
+	// Period is the median time between the starts of consecutive builds
+	Period time.Duration
+	// Intervals is the number of intervals Period was inferred from
+	Intervals int
+	LastRun   time.Time
+}
+
+// InferCadence infers the run period of a tab from its build start times
+//
+// The median is used so that a few retried or skipped runs do not skew the
+// period. It returns false when there are too few builds with start times.
+func InferCadence(headers []client.Header) (Cadence, bool) {
+	var starts []time.Time
+	for _, h := range headers {
+		if t, err := client.ParseTimestamp(h.Started); err == nil {
+			starts = append(starts, t)
+		}
+	}
+	if len(starts) == 0 {
+		return Cadence{}, false
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return b.Compare(a) })
+
+	var intervals []time.Duration
+	for i := 1; i < len(starts); i++ {
+		if d := starts[i-1].Sub(starts[i]); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	c := Cadence{LastRun: starts[0], Intervals: len(intervals)}
+	if len(intervals) < minIntervals {
+		return c, false
+	}
+	slices.Sort(intervals)
+	c.Period = intervals[len(intervals)/2]
+	return c, true
+}
+
+// Overdue returns how many periods have passed since the last run
+func (c Cadence) Overdue(now time.Time) float64 {
+	if c.Period <= 0 {
+		return 0
+	}
+	return float64(now.Sub(c.LastRun)) / float64(c.Period)
+}
+
+// StaleAPI is the subset of the TestGrid client used to find stale tabs
+type StaleAPI interface {
+	ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error)
+}
+
+// StaleOptions controls which tabs are reported as overdue
+type StaleOptions struct {
+	// Multiple is the number of periods after which a tab is overdue
+	Multiple    float64
+	Concurrency int
+	// All reports every tab with an inferred cadence, not only overdue ones
+	All bool
+	Now time.Time
+}
+
+// StaleTab is a tab with its inferred cadence
+type StaleTab struct {
+	Dashboard string    `json:"dashboard"`
+	Tab       string    `json:"tab"`
+	Period    string    `json:"period"`
+	LastRun   time.Time `json:"last_run"`
+	Since     string    `json:"since"`
+	// Overdue is the time since the last run in periods
+	Overdue float64 `json:"overdue"`
+	// Status is the status TestGrid reports for the tab
+	Status string `json:"status,omitempty"`
+}
+
+// StaleResult lists the overdue tabs of a set of dashboards
+type StaleResult struct {
+	Tabs []StaleTab `json:"tabs"`
+	// Checked is the number of tabs whose cadence could be inferred
+	Checked int `json:"checked"`
+	// Uninferred is the number of tabs with too few builds for a cadence
+	Uninferred int      `json:"uninferred"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// FindStale infers the cadence of every tab in dashboards and returns the
+// tabs whose last run is more than opts.Multiple periods ago, most overdue
+// first
+func FindStale(ctx context.Context, api StaleAPI, dashboards []string, opts StaleOptions) *StaleResult {
+	if opts.Multiple <= 0 {
+		opts.Multiple = DefaultOverdueMultiple
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	res := &StaleResult{}
+
+	var mu sync.Mutex
+	g := parallel.NewGroup(opts.Concurrency)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		res.Errors = append(res.Errors, err.Error())
+	}
+
+	for _, d := range dashboards {
+		g.Go(func() {
+			tabs, err := api.ListDashboardTabs(ctx, d)
+			if err != nil {
+				fail(fmt.Errorf("%s: %w", d, err))
+				return
+			}
+			// Statuses are informational, so a failure to list them is not fatal
+			status := make(map[string]string)
+			if summaries, err := api.ListTabSummaries(ctx, d); err == nil {
+				for _, s := range summaries.TabSummaries {
+					status[s.TabName] = s.OverallStatus
+				}
+			}
+
+			for _, t := range tabs.DashboardTabs {
+				g.Go(func() {
+					headers, err := api.GetTabHeaders(ctx, d, t.Name)
+					if err != nil {
+						fail(fmt.Errorf("%s/%s: %w", d, t.Name, err))
+						return
+					}
+					c, ok := InferCadence(headers.Headers)
+
+					mu.Lock()
+					defer mu.Unlock()
+					if !ok {
+						res.Uninferred++
+						return
+					}
+					res.Checked++
+					overdue := c.Overdue(opts.Now)
+					if overdue < opts.Multiple && !opts.All {
+						return
+					}
+					res.Tabs = append(res.Tabs, StaleTab{
+						Dashboard: d,
+						Tab:       t.Name,
+						Period:    c.Period.Round(time.Minute).String(),
+						LastRun:   c.LastRun,
+						Since:     opts.Now.Sub(c.LastRun).Round(time.Minute).String(),
+						Overdue:   overdue,
+						Status:    status[t.Name],
+					})
+				})
+			}
+		})
+	}
+	g.Wait()
+
+	slices.SortFunc(res.Tabs, func(a, b StaleTab) int {
+		return cmp.Or(cmp.Compare(b.Overdue, a.Overdue), cmp.Compare(a.Dashboard, b.Dashboard), cmp.Compare(a.Tab, b.Tab))
+	})
+	slices.Sort(res.Errors)
+	return res
+}
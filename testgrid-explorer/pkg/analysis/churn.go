@@ -0,0 +1,223 @@
+package analysis
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+)
+
+// ChurnOptions controls how added, removed and renamed tests are detected
+type ChurnOptions struct {
+	// Since splits the grid into baseline columns that started before it
+	// and recent columns that started at or after it
+	Since time.Time `json:"since"`
+	// MinSimilarity is the normalized name similarity, between 0 and 1,
+	// above which a removed and an added test may be a rename
+	MinSimilarity float64 `json:"min_similarity"`
+	// Window is the number of results compared between a removed test's
+	// last runs and an added test's first runs
+	Window int `json:"window"`
+}
+
+// DefaultChurnOptions are the options used for zero fields
+var DefaultChurnOptions = ChurnOptions{MinSimilarity: 0.8, Window: 5}
+
+// ChurnTest is a test that appeared or vanished
+type ChurnTest struct {
+	Name string `json:"name"`
+	// FirstRun and LastRun are the builds of its oldest and newest results
+	FirstRun string `json:"first_run,omitempty"`
+	LastRun  string `json:"last_run,omitempty"`
+	Runs     int    `json:"runs"`
+	// Results are its non-empty results, oldest first
+	Results []int `json:"-"`
+}
+
+// Rename is a removed test that was likely renamed to an added one
+type Rename struct {
+	From       ChurnTest `json:"from"`
+	To         ChurnTest `json:"to"`
+	Similarity float64   `json:"similarity"`
+}
+
+// Churn are the tests that changed between the baseline and recent columns
+type Churn struct {
+	Options ChurnOptions `json:"options"`
+	// Baseline and Recent are the number of columns on each side of Since
+	Baseline int `json:"baseline_builds"`
+	Recent   int `json:"recent_builds"`
+	// Tests is the number of tests that ran in the baseline
+	Tests   int         `json:"tests"`
+	Added   []ChurnTest `json:"added"`
+	Removed []ChurnTest `json:"removed"`
+	Renamed []Rename    `json:"renamed"`
+}
+
+// DetectChurn compares the tests that ran before opts.Since with those that
+// ran after it
+//
+// A test is removed when it has results in the baseline but none since, and
+// added when it is the other way around. A removed and an added test are
+// reported as a rename instead when their normalized names are similar and
+// the removed test's last results equal the added test's first results.
+// Columns without a start time are ignored.
+func DetectChurn(headers []client.Header, rows []client.Row, opts ChurnOptions) (*Churn, error) {
+	if opts.MinSimilarity <= 0 {
+		opts.MinSimilarity = DefaultChurnOptions.MinSimilarity
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultChurnOptions.Window
+	}
+	out := &Churn{Options: opts, Added: []ChurnTest{}, Removed: []ChurnTest{}, Renamed: []Rename{}}
+
+	recent := make([]bool, len(headers))
+	ignored := make([]bool, len(headers))
+	for i, h := range headers {
+		started, err := client.ParseTimestamp(h.Started)
+		if err != nil {
+			ignored[i] = true
+			continue
+		}
+		if started.Before(opts.Since) {
+			out.Baseline++
+		} else {
+			recent[i] = true
+			out.Recent++
+		}
+	}
+	if out.Recent == 0 {
+		return nil, errors.New("no builds started since the cutoff")
+	}
+	if out.Baseline == 0 {
+		return nil, errors.New("no builds started before the cutoff")
+	}
+
+	for _, r := range rows {
+		if r.Name == client.OverallRow {
+			continue
+		}
+		before := ChurnTest{Name: r.Name}
+		after := ChurnTest{Name: r.Name}
+		// Cells are most recent first, so walk them backwards to collect
+		// results oldest first
+		for i := min(len(r.Cells), len(headers)) - 1; i >= 0; i-- {
+			if ignored[i] || r.Cells[i].Result == client.CellResultEmpty {
+				continue
+			}
+			t := &before
+			if recent[i] {
+				t = &after
+			}
+			if t.FirstRun == "" {
+				t.FirstRun = headers[i].Build
+			}
+			t.LastRun = headers[i].Build
+			t.Runs++
+			t.Results = append(t.Results, r.Cells[i].Result)
+		}
+		switch {
+		case before.Runs > 0 && after.Runs == 0:
+			out.Removed = append(out.Removed, before)
+		case before.Runs == 0 && after.Runs > 0:
+			out.Added = append(out.Added, after)
+		}
+		if before.Runs > 0 {
+			out.Tests++
+		}
+	}
+
+	matchRenames(out)
+	byName := func(a, b ChurnTest) int { return cmp.Compare(a.Name, b.Name) }
+	slices.SortFunc(out.Added, byName)
+	slices.SortFunc(out.Removed, byName)
+	slices.SortFunc(out.Renamed, func(a, b Rename) int { return cmp.Compare(a.From.Name, b.From.Name) })
+	return out, nil
+}
+
+// matchRenames moves the most similar removed and added pairs to c.Renamed,
+// pairing each test at most once
+func matchRenames(c *Churn) {
+	var candidates []Rename
+	for _, from := range c.Removed {
+		for _, to := range c.Added {
+			if !sameResults(from.Results, to.Results, c.Options.Window) {
+				continue
+			}
+			if s := NameSimilarity(from.Name, to.Name); s >= c.Options.MinSimilarity {
+				candidates = append(candidates, Rename{From: from, To: to, Similarity: s})
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b Rename) int { return cmp.Compare(b.Similarity, a.Similarity) })
+
+	renamed := make(map[string]bool)
+	for _, r := range candidates {
+		if renamed[r.From.Name] || renamed[r.To.Name] {
+			continue
+		}
+		renamed[r.From.Name] = true
+		renamed[r.To.Name] = true
+		c.Renamed = append(c.Renamed, r)
+	}
+	keep := func(tests []ChurnTest) []ChurnTest {
+		return slices.DeleteFunc(tests, func(t ChurnTest) bool { return renamed[t.Name] })
+	}
+	c.Removed = keep(c.Removed)
+	c.Added = keep(c.Added)
+}
+
+// sameResults reports whether the last results of before equal the first
+// results of after, comparing at most window of them
+func sameResults(before, after []int, window int) bool {
+	n := min(len(before), len(after), window)
+	return n > 0 && slices.Equal(before[len(before)-n:], after[:n])
+}
+
+// NameSimilarity compares two test names by edit distance after
+// normalization, from 0 (unrelated) to 1 (equal)
+//
+// Normalization drops bracketed tags and the suite, lowercases, and collapses
+// punctuation, so retagging a test or moving it between SIGs does not
+// lower its similarity.
+func NameSimilarity(a, b string) float64 {
+	na, nb := []rune(normalizeName(a)), []rune(normalizeName(b))
+	longest := max(len(na), len(nb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(na, nb))/float64(longest)
+}
+
+func normalizeName(name string) string {
+	desc := testname.Parse(name).Description
+	words := strings.FieldsFunc(strings.ToLower(desc), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
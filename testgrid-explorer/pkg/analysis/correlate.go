@@ -0,0 +1,247 @@
+package analysis
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Failure labels
+const (
+	// Widespread failures failed in MinTabs or more tabs at the same point
+	Widespread = "widespread"
+	// Localized failures failed in one tab while passing in another
+	Localized = "localized"
+	// Unaligned failures failed in a tab no other tab ran alongside
+	Unaligned = "unaligned"
+)
+
+// CorrelateOptions controls how builds are aligned across tabs
+type CorrelateOptions struct {
+	// Builds is the number of recent columns considered per tab, 0 for all
+	Builds int `json:"builds"`
+	// TimeWindow buckets columns without a commit by start time
+	TimeWindow time.Duration `json:"-"`
+	// ByTime aligns every column by start time, ignoring commits
+	ByTime bool `json:"by_time"`
+	// MinTabs is the number of failing tabs that makes a failure widespread
+	MinTabs int `json:"min_tabs"`
+}
+
+// DefaultCorrelateOptions are the options used for zero fields
+var DefaultCorrelateOptions = CorrelateOptions{Builds: 10, TimeWindow: 2 * time.Hour, MinTabs: 2}
+
+// CoFailure is one point at which a test failed in at least one tab
+type CoFailure struct {
+	// Key is the commit or the start of the time bucket the tabs share
+	Key string `json:"key"`
+	// By is "commit" or "time"
+	By     string   `json:"by"`
+	Failed []string `json:"failed"`
+	Passed []string `json:"passed,omitempty"`
+	Label  string   `json:"label"`
+}
+
+// TestCorrelation are the co-failure statistics of one test
+type TestCorrelation struct {
+	Test string `json:"test"`
+	// Label is the most common label of the test's failures, preferring
+	// widespread over localized over unaligned on ties
+	Label      string `json:"label"`
+	Failures   int    `json:"failures"`
+	Widespread int    `json:"widespread"`
+	Localized  int    `json:"localized"`
+	Unaligned  int    `json:"unaligned"`
+	// CoFailureRate is the share of tabs that ran the test and failed,
+	// averaged over failures where at least two tabs ran it, or -1
+	CoFailureRate float64 `json:"co_failure_rate"`
+	// Tabs are the tabs the test failed in
+	Tabs []string `json:"tabs"`
+	// Events are the test's failures, most recent first
+	Events []CoFailure `json:"events"`
+}
+
+// Correlation labels the failures of every test across the tabs of a dashboard
+type Correlation struct {
+	Dashboard string           `json:"dashboard"`
+	Options   CorrelateOptions `json:"options"`
+	Tabs      []string         `json:"tabs"`
+	// Shared is the number of commits or time buckets seen by two or more tabs
+	Shared int               `json:"shared"`
+	Tests  []TestCorrelation `json:"tests"`
+}
+
+// point is a column's alignment key
+type point struct {
+	key, by string
+}
+
+// Correlate aligns the columns of tabs and labels every failure of every test
+//
+// Columns are aligned by the commit in their first header extra, or by start
+// time bucketed to opts.TimeWindow when they have none or opts.ByTime is set;
+// columns with neither are ignored. A test failed at a point if any of a
+// tab's columns there failed and passed if none failed and one passed.
+// Tests are ordered by widespread failures, then failures, then name. The
+// Overall row is left out.
+func Correlate(dashboard string, tabs []TabGrid, opts CorrelateOptions) *Correlation {
+	if opts.TimeWindow <= 0 {
+		opts.TimeWindow = DefaultCorrelateOptions.TimeWindow
+	}
+	if opts.MinTabs <= 0 {
+		opts.MinTabs = DefaultCorrelateOptions.MinTabs
+	}
+	out := &Correlation{Dashboard: dashboard, Options: opts, Tests: []TestCorrelation{}}
+
+	// results[test][point][tab] is the test's result in the tab at the point
+	results := make(map[string]map[point]map[string]int)
+	tabsAt := make(map[point]map[string]bool)
+	latest := make(map[point]time.Time)
+	var order []point
+	for _, t := range tabs {
+		out.Tabs = append(out.Tabs, t.Tab)
+		points := make([]*point, len(t.Headers))
+		for i, h := range t.Headers {
+			if opts.Builds > 0 && i == opts.Builds {
+				break
+			}
+			p, ok := alignColumn(h, opts)
+			if !ok {
+				continue
+			}
+			points[i] = &p
+			if tabsAt[p] == nil {
+				tabsAt[p] = make(map[string]bool)
+				order = append(order, p)
+			}
+			tabsAt[p][t.Tab] = true
+			if started, err := client.ParseTimestamp(h.Started); err == nil && started.After(latest[p]) {
+				latest[p] = started
+			}
+		}
+
+		for _, r := range t.Rows {
+			if r.Name == client.OverallRow {
+				continue
+			}
+			for i, c := range r.Cells {
+				if i >= len(points) || points[i] == nil {
+					continue
+				}
+				if c.Result != client.CellResultPass && c.Result != client.CellResultFail {
+					continue
+				}
+				if results[r.Name] == nil {
+					results[r.Name] = make(map[point]map[string]int)
+				}
+				byTab := results[r.Name][*points[i]]
+				if byTab == nil {
+					byTab = make(map[string]int)
+					results[r.Name][*points[i]] = byTab
+				}
+				if byTab[t.Tab] != client.CellResultFail {
+					byTab[t.Tab] = c.Result
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(order, func(a, b point) int { return latest[b].Compare(latest[a]) })
+	for _, p := range order {
+		if len(tabsAt[p]) > 1 {
+			out.Shared++
+		}
+	}
+
+	for test, points := range results {
+		tc := TestCorrelation{Test: test, CoFailureRate: -1}
+		failedTabs := make(map[string]bool)
+		var rates []float64
+		for _, p := range order {
+			byTab, ok := points[p]
+			if !ok {
+				continue
+			}
+			e := CoFailure{Key: p.key, By: p.by}
+			for tab, result := range byTab {
+				if result == client.CellResultFail {
+					e.Failed = append(e.Failed, tab)
+					failedTabs[tab] = true
+				} else {
+					e.Passed = append(e.Passed, tab)
+				}
+			}
+			if len(e.Failed) == 0 {
+				continue
+			}
+			slices.Sort(e.Failed)
+			slices.Sort(e.Passed)
+
+			switch {
+			case len(e.Failed) >= opts.MinTabs:
+				e.Label = Widespread
+				tc.Widespread++
+			case len(e.Passed) > 0:
+				e.Label = Localized
+				tc.Localized++
+			default:
+				e.Label = Unaligned
+				tc.Unaligned++
+			}
+			if ran := len(e.Failed) + len(e.Passed); ran > 1 {
+				rates = append(rates, float64(len(e.Failed))/float64(ran))
+			}
+			tc.Failures++
+			tc.Events = append(tc.Events, e)
+		}
+		if tc.Failures == 0 {
+			continue
+		}
+
+		if len(rates) > 0 {
+			sum := 0.0
+			for _, r := range rates {
+				sum += r
+			}
+			tc.CoFailureRate = sum / float64(len(rates))
+		}
+		for tab := range failedTabs {
+			tc.Tabs = append(tc.Tabs, tab)
+		}
+		slices.Sort(tc.Tabs)
+		switch {
+		case tc.Widespread > 0 && tc.Widespread >= max(tc.Localized, tc.Unaligned):
+			tc.Label = Widespread
+		case tc.Localized > 0 && tc.Localized >= tc.Unaligned:
+			tc.Label = Localized
+		default:
+			tc.Label = Unaligned
+		}
+		out.Tests = append(out.Tests, tc)
+	}
+
+	slices.SortFunc(out.Tests, func(a, b TestCorrelation) int {
+		return cmp.Or(
+			cmp.Compare(b.Widespread, a.Widespread),
+			cmp.Compare(b.Failures, a.Failures),
+			cmp.Compare(a.Test, b.Test),
+		)
+	})
+	return out
+}
+
+// alignColumn returns the point a column is aligned at
+func alignColumn(h client.Header, opts CorrelateOptions) (point, bool) {
+	if !opts.ByTime && len(h.Extra) > 0 {
+		if commit := strings.TrimSpace(h.Extra[0]); commit != "" {
+			return point{key: commit, by: "commit"}, true
+		}
+	}
+	started, err := client.ParseTimestamp(h.Started)
+	if err != nil {
+		return point{}, false
+	}
+	return point{key: started.UTC().Truncate(opts.TimeWindow).Format(time.RFC3339), by: "time"}, true
+}
@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Run is one result of a test joined with its column header
+type Run struct {
+	Build   string `json:"build"`
+	Started string `json:"started,omitempty"`
+	// Commit is the first header extra, usually the commit under test
+	Commit  string `json:"commit,omitempty"`
+	Result  int    `json:"result"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// URL links to the build's results, if known
+	URL string `json:"url,omitempty"`
+	// Infra marks runs in infra failure columns, which are left out of stats
+	Infra bool `json:"infra,omitempty"`
+}
+
+// HistoryStats summarizes a test's runs
+type HistoryStats struct {
+	Runs     int `json:"runs"`
+	Passes   int `json:"passes"`
+	Failures int `json:"failures"`
+	Skipped  int `json:"skipped"`
+	// PassRate is Passes / (Passes + Failures), or -1 if neither occurred
+	PassRate float64 `json:"pass_rate"`
+	// Consecutive is the number of failures since the last pass
+	Consecutive int `json:"consecutive_failures"`
+	// Flips counts changes between pass and fail, a measure of flakiness
+	Flips       int    `json:"flips"`
+	LastPass    string `json:"last_pass,omitempty"`
+	LastFailure string `json:"last_failure,omitempty"`
+}
+
+// TestHistory is the history of one test in a tab, most recent first
+type TestHistory struct {
+	Dashboard string       `json:"dashboard"`
+	Tab       string       `json:"tab"`
+	Test      string       `json:"test"`
+	Stats     HistoryStats `json:"stats"`
+	Runs      []Run        `json:"runs"`
+}
+
+// FindRow returns the row named test, matched like dashboard and tab names
+// so that case-insensitive and unique prefix matches work
+func FindRow(rows []client.Row, test string) (*client.Row, error) {
+	candidates := make([]client.Candidate, 0, len(rows))
+	for _, r := range rows {
+		candidates = append(candidates, client.Candidate{Name: r.Name})
+	}
+	name, err := client.Match("test", test, candidates)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if rows[i].Name == name {
+			return &rows[i], nil
+		}
+	}
+	return nil, &client.ResolveError{Kind: "test", Input: test}
+}
+
+// BuildHistory joins the cells of row with headers, skipping columns in
+// which the test did not run
+//
+// Runs in the infra columns are marked and not counted in the stats.
+func BuildHistory(dashboard, tab string, headers []client.Header, row client.Row, infra []InfraColumn) *TestHistory {
+	h := &TestHistory{Dashboard: dashboard, Tab: tab, Test: row.Name, Stats: HistoryStats{PassRate: -1}}
+	s := &h.Stats
+	last := client.CellResultEmpty
+	passed := false
+
+	infraCols := make(map[int]bool, len(infra))
+	for _, ic := range infra {
+		infraCols[ic.Column] = true
+	}
+
+	for i, c := range row.Cells {
+		if c.Result == client.CellResultEmpty {
+			continue
+		}
+		run := Run{Result: c.Result, Status: client.CellResultString(c.Result), Message: c.Message}
+		if i < len(headers) {
+			run.Build = headers[i].Build
+			run.Started = headers[i].Started
+			if len(headers[i].Extra) > 0 {
+				run.Commit = strings.TrimSpace(headers[i].Extra[0])
+			}
+		}
+		run.Infra = infraCols[i]
+		h.Runs = append(h.Runs, run)
+		if run.Infra {
+			continue
+		}
+
+		s.Runs++
+		switch c.Result {
+		case client.CellResultPass:
+			s.Passes++
+			passed = true
+			if s.LastPass == "" {
+				s.LastPass = run.Build
+			}
+		case client.CellResultFail:
+			s.Failures++
+			if !passed {
+				s.Consecutive++
+			}
+			if s.LastFailure == "" {
+				s.LastFailure = run.Build
+			}
+		default:
+			s.Skipped++
+			continue
+		}
+		if last != client.CellResultEmpty && last != c.Result {
+			s.Flips++
+		}
+		last = c.Result
+	}
+
+	if s.Passes+s.Failures > 0 {
+		s.PassRate = float64(s.Passes) / float64(s.Passes+s.Failures)
+	}
+	return h
+}
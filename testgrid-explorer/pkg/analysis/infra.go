@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Defaults for InfraOptions
+const (
+	DefaultInfraFailRatio  = 0.5
+	DefaultInfraEmptyRatio = 0.9
+	DefaultInfraMinTests   = 10
+)
+
+// InfraOptions tunes when a column counts as an infrastructure failure
+type InfraOptions struct {
+	// FailRatio is the share of tests that ran and failed above which a
+	// column is an infra failure
+	FailRatio float64
+	// EmptyRatio is the share of tests that did not run above which a
+	// column whose Overall row failed is an infra failure
+	EmptyRatio float64
+	// MinTests is the number of tests a column needs for FailRatio to
+	// apply, so that small tabs failing for real are not mistaken for infra
+	MinTests int
+}
+
+// DefaultInfraOptions are the thresholds used unless configured otherwise
+var DefaultInfraOptions = InfraOptions{
+	FailRatio:  DefaultInfraFailRatio,
+	EmptyRatio: DefaultInfraEmptyRatio,
+	MinTests:   DefaultInfraMinTests,
+}
+
+// InfraColumn is a build column whose failures are most likely caused by
+// the infrastructure rather than by the code under test
+type InfraColumn struct {
+	Column int    `json:"column"`
+	Build  string `json:"build,omitempty"`
+	Reason string `json:"reason"`
+	Ran    int    `json:"ran"`
+	Failed int    `json:"failed"`
+	// Tests is the number of tests with any result in the tab
+	Tests int `json:"tests"`
+}
+
+// DetectInfraFailures classifies the columns of a tab
+//
+// A column is an infra failure if at least opts.FailRatio of the tests that
+// ran in it failed, or if its Overall row failed while at least
+// opts.EmptyRatio of the tab's tests did not run, as when a cluster fails to
+// come up. headers may be nil; they only provide build IDs.
+func DetectInfraFailures(headers []client.Header, rows []client.Row, opts InfraOptions) []InfraColumn {
+	width, tests := 0, 0
+	var overall *client.Row
+	for i, r := range rows {
+		if r.Name == client.OverallRow {
+			overall = &rows[i]
+			continue
+		}
+		width = max(width, len(r.Cells))
+		for _, c := range r.Cells {
+			if c.Result != client.CellResultEmpty {
+				tests++
+				break
+			}
+		}
+	}
+	if tests == 0 {
+		return nil
+	}
+
+	var out []InfraColumn
+	for col := range width {
+		ic := InfraColumn{Column: col, Tests: tests}
+		if col < len(headers) {
+			ic.Build = headers[col].Build
+		}
+		for _, r := range rows {
+			if r.Name == client.OverallRow || col >= len(r.Cells) {
+				continue
+			}
+			switch r.Cells[col].Result {
+			case client.CellResultEmpty:
+			case client.CellResultFail:
+				ic.Ran++
+				ic.Failed++
+			default:
+				ic.Ran++
+			}
+		}
+
+		overallFailed := overall != nil && col < len(overall.Cells) && overall.Cells[col].Result == client.CellResultFail
+		switch {
+		case ic.Ran >= max(opts.MinTests, 1) && float64(ic.Failed) >= opts.FailRatio*float64(ic.Ran):
+			ic.Reason = fmt.Sprintf("%d of %d tests failed", ic.Failed, ic.Ran)
+		case overallFailed && float64(tests-ic.Ran) >= opts.EmptyRatio*float64(tests):
+			ic.Reason = fmt.Sprintf("build failed with %d of %d tests not run", tests-ic.Ran, tests)
+		default:
+			continue
+		}
+		out = append(out, ic)
+	}
+	return out
+}
+
+// MaskColumns returns a copy of rows with the cells of cols emptied, so
+// that they are ignored like builds in which the tests did not run
+func MaskColumns(rows []client.Row, cols []InfraColumn) []client.Row {
+	if len(cols) == 0 {
+		return rows
+	}
+	out := make([]client.Row, len(rows))
+	for i, r := range rows {
+		cells := make([]client.Cell, len(r.Cells))
+		copy(cells, r.Cells)
+		for _, ic := range cols {
+			if ic.Column < len(cells) {
+				cells[ic.Column] = client.Cell{}
+			}
+		}
+		out[i] = client.Row{Name: r.Name, Cells: cells}
+	}
+	return out
+}
+
+// ExcludeInfraFailures masks the columns of rows detected as infra failures
+// with the default thresholds
+func ExcludeInfraFailures(rows []client.Row) []client.Row {
+	return MaskColumns(rows, DetectInfraFailures(nil, rows, DefaultInfraOptions))
+}
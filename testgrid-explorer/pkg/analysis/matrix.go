@@ -0,0 +1,221 @@
+package analysis
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// DefaultConcurrency is the number of tabs fetched at once
+const DefaultConcurrency = 8
+
+// RowsAPI is the subset of the TestGrid client used to fetch a dashboard's grids
+type RowsAPI interface {
+	ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// TabRows are the rows of one tab
+type TabRows struct {
+	Dashboard string       `json:"dashboard,omitempty"`
+	Tab       string       `json:"tab"`
+	Rows      []client.Row `json:"rows"`
+}
+
+// FetchDashboardRows fetches the rows of every tab in dashboard concurrently,
+// in the dashboard's tab order
+//
+// Tabs that cannot be fetched are skipped and their errors returned together
+// with the tabs that could.
+func FetchDashboardRows(ctx context.Context, api RowsAPI, dashboard string, concurrency int) ([]TabRows, []error, error) {
+	return fetchTabs(ctx, api, dashboard, concurrency, func(tab string) (TabRows, error) {
+		resp, err := api.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return TabRows{}, err
+		}
+		return TabRows{Dashboard: dashboard, Tab: tab, Rows: resp.Rows}, nil
+	})
+}
+
+// GridAPI is the subset of the TestGrid client used to fetch a dashboard's
+// grids with their column headers
+type GridAPI interface {
+	RowsAPI
+	GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error)
+}
+
+// TabGrid is the headers and rows of one tab
+type TabGrid struct {
+	Tab     string          `json:"tab"`
+	Headers []client.Header `json:"headers"`
+	Rows    []client.Row    `json:"rows"`
+}
+
+// FetchDashboardGrids is FetchDashboardRows that also fetches each tab's headers
+func FetchDashboardGrids(ctx context.Context, api GridAPI, dashboard string, concurrency int) ([]TabGrid, []error, error) {
+	return fetchTabs(ctx, api, dashboard, concurrency, func(tab string) (TabGrid, error) {
+		headers, err := api.GetTabHeaders(ctx, dashboard, tab)
+		if err != nil {
+			return TabGrid{}, err
+		}
+		rows, err := api.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return TabGrid{}, err
+		}
+		return TabGrid{Tab: tab, Headers: headers.Headers, Rows: rows.Rows}, nil
+	})
+}
+
+// fetchTabs calls fetch for every tab of dashboard with at most concurrency
+// calls at once, returning the results in tab order
+func fetchTabs[T any](ctx context.Context, api RowsAPI, dashboard string, concurrency int, fetch func(tab string) (T, error)) ([]T, []error, error) {
+	tabs, err := api.ListDashboardTabs(ctx, dashboard)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing tabs of %s: %w", dashboard, err)
+	}
+
+	results := make([]T, len(tabs.DashboardTabs))
+	errs := make([]error, len(tabs.DashboardTabs))
+	g := parallel.NewGroup(concurrency)
+	for i, t := range tabs.DashboardTabs {
+		g.Go(func() {
+			results[i], errs[i] = fetch(t.Name)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s/%s: %w", dashboard, t.Name, errs[i])
+			}
+		})
+	}
+	g.Wait()
+
+	var out []T
+	var tabErrs []error
+	for i := range results {
+		if errs[i] != nil {
+			tabErrs = append(tabErrs, errs[i])
+			continue
+		}
+		out = append(out, results[i])
+	}
+	return out, tabErrs, nil
+}
+
+// MatrixCell summarizes one test in one tab
+type MatrixCell struct {
+	// Result is the most recent non-empty result and Latest its name
+	Result int    `json:"result"`
+	Latest string `json:"latest"`
+	// Message is the message of the latest result
+	Message string `json:"message,omitempty"`
+	// LastFailure is the message of the most recent failure in the window
+	LastFailure string `json:"last_failure,omitempty"`
+	Runs        int    `json:"runs"`
+	Passes      int    `json:"passes"`
+	// PassRate is Passes / Runs over the window, or -1 without runs
+	PassRate float64 `json:"pass_rate"`
+}
+
+// Failing reports whether the latest result failed
+func (c MatrixCell) Failing() bool {
+	return c.Result == client.CellResultFail
+}
+
+// MatrixRow is one test across every tab
+type MatrixRow struct {
+	Test string `json:"test"`
+	// Cells is aligned with Matrix.Tabs; nil where the test is not in a tab
+	Cells       []*MatrixCell `json:"cells"`
+	FailingTabs int           `json:"failing_tabs"`
+}
+
+// Matrix is a dashboard's tests (rows) by tabs (columns)
+type Matrix struct {
+	Dashboard string      `json:"dashboard"`
+	Tabs      []string    `json:"tabs"`
+	Window    int         `json:"window"`
+	Rows      []MatrixRow `json:"rows"`
+}
+
+// BuildMatrix combines the rows of tabs into a matrix
+//
+// Each cell holds the latest non-empty result of the test in the tab and its
+// pass rate over the last window results. Rows are ordered by the number of
+// tabs they fail in, then by name. The Overall row is left out.
+func BuildMatrix(dashboard string, tabs []TabRows, window int) *Matrix {
+	m := &Matrix{Dashboard: dashboard, Window: window}
+	byTest := make(map[string]*MatrixRow)
+
+	for col, t := range tabs {
+		m.Tabs = append(m.Tabs, t.Tab)
+		for _, r := range t.Rows {
+			if r.Name == client.OverallRow {
+				continue
+			}
+			row, ok := byTest[r.Name]
+			if !ok {
+				row = &MatrixRow{Test: r.Name, Cells: make([]*MatrixCell, len(tabs))}
+				byTest[r.Name] = row
+			}
+			cell := SummarizeCells(r.Cells, window)
+			row.Cells[col] = &cell
+			if cell.Failing() {
+				row.FailingTabs++
+			}
+		}
+	}
+
+	for _, row := range byTest {
+		m.Rows = append(m.Rows, *row)
+	}
+	slices.SortFunc(m.Rows, func(a, b MatrixRow) int {
+		return cmp.Or(cmp.Compare(b.FailingTabs, a.FailingTabs), cmp.Compare(a.Test, b.Test))
+	})
+	return m
+}
+
+// SummarizeCells summarizes the most recent window non-empty cells of a row
+func SummarizeCells(cells []client.Cell, window int) MatrixCell {
+	c := MatrixCell{PassRate: -1}
+	for _, cell := range cells {
+		if window > 0 && c.Runs == window {
+			break
+		}
+		if cell.Result == client.CellResultEmpty {
+			continue
+		}
+		if c.Latest == "" {
+			c.Result = cell.Result
+			c.Latest = client.CellResultString(cell.Result)
+			c.Message = cell.Message
+		}
+		switch cell.Result {
+		case client.CellResultPass:
+			c.Runs++
+			c.Passes++
+		case client.CellResultFail:
+			c.Runs++
+			if c.LastFailure == "" {
+				c.LastFailure = cell.Message
+			}
+		}
+	}
+	if c.Runs > 0 {
+		c.PassRate = float64(c.Passes) / float64(c.Runs)
+	}
+	return c
+}
+
+// FilterMinFailing returns a copy of m keeping only tests failing in at least k tabs
+func (m *Matrix) FilterMinFailing(k int) *Matrix {
+	out := *m
+	out.Rows = nil
+	for _, r := range m.Rows {
+		if r.FailingTabs >= k {
+			out.Rows = append(out.Rows, r)
+		}
+	}
+	return &out
+}
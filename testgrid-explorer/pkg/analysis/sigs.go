@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+)
+
+// SIGStats aggregates the tests owned by one SIG
+type SIGStats struct {
+	SIG        string `json:"sig"`
+	Dashboards int    `json:"dashboards"`
+	Tabs       int    `json:"tabs"`
+	Tests      int    `json:"tests"`
+	// Failing counts tests with consecutive failures since their last pass
+	Failing int `json:"failing"`
+	// Flaky counts tests that failed within the window but passed since
+	Flaky int `json:"flaky"`
+	// FailingTabs counts tabs with at least one failing test of the SIG
+	FailingTabs int `json:"failing_tabs"`
+	Runs        int `json:"runs"`
+	Passes      int `json:"passes"`
+	// PassRate is Passes / Runs over the window, or -1 without runs
+	PassRate float64 `json:"pass_rate"`
+	// Offenders are the failing tests, then the flaky ones, each by
+	// consecutive failures, then failures
+	Offenders []SIGTest `json:"offenders,omitempty"`
+}
+
+// SIGTest is a test that failed within the window
+type SIGTest struct {
+	Dashboard string `json:"dashboard,omitempty"`
+	Tab       string `json:"tab"`
+	Test      string `json:"test"`
+	// Failing is set when Consecutive is positive, otherwise the test is flaky
+	Failing  bool `json:"failing"`
+	Failures int  `json:"failures"`
+	Runs     int  `json:"runs"`
+	// Consecutive is the number of failures since the last pass
+	Consecutive int `json:"consecutive"`
+	// Message is the message of the most recent failure
+	Message string `json:"message,omitempty"`
+}
+
+// GroupBySIG aggregates the last window results of every test in tabs by
+// the SIG parsed from its name
+//
+// Tests without a [sig-*] tag belong to the SIG in their tab's dashboard
+// name, or to testname.NoSIG. A test in several tabs is counted once per
+// tab and the Overall row is left out. SIGs are ordered by failing tests,
+// then failing tabs and flaky tests, then by name.
+func GroupBySIG(tabs []TabRows, window int) []SIGStats {
+	bySIG := make(map[string]*SIGStats)
+	dashboards := make(map[string]map[string]bool)
+	// Tabs are counted by dashboard and name, as names repeat across dashboards
+	tabKeys := make(map[string]map[string]bool)
+	failingTabs := make(map[string]map[string]bool)
+	for _, t := range tabs {
+		dashboardSIG := testname.DashboardSIG(t.Dashboard)
+		tabKey := t.Dashboard + "/" + t.Tab
+		for _, r := range t.Rows {
+			if r.Name == client.OverallRow {
+				continue
+			}
+			sig := cmp.Or(testname.Parse(r.Name).SIG, dashboardSIG, testname.NoSIG)
+			s, ok := bySIG[sig]
+			if !ok {
+				s = &SIGStats{SIG: sig}
+				bySIG[sig] = s
+				dashboards[sig] = make(map[string]bool)
+				tabKeys[sig] = make(map[string]bool)
+				failingTabs[sig] = make(map[string]bool)
+			}
+			if t.Dashboard != "" {
+				dashboards[sig][t.Dashboard] = true
+			}
+			tabKeys[sig][tabKey] = true
+
+			c := SummarizeCells(r.Cells, window)
+			s.Tests++
+			s.Runs += c.Runs
+			s.Passes += c.Passes
+			if c.Passes == c.Runs {
+				continue
+			}
+			consecutive := ConsecutiveFailures(r.Cells, window)
+			if consecutive > 0 {
+				s.Failing++
+				failingTabs[sig][tabKey] = true
+			} else {
+				s.Flaky++
+			}
+			s.Offenders = append(s.Offenders, SIGTest{
+				Dashboard:   t.Dashboard,
+				Tab:         t.Tab,
+				Test:        r.Name,
+				Failing:     consecutive > 0,
+				Failures:    c.Runs - c.Passes,
+				Runs:        c.Runs,
+				Consecutive: consecutive,
+				Message:     c.LastFailure,
+			})
+		}
+	}
+
+	out := make([]SIGStats, 0, len(bySIG))
+	for sig, s := range bySIG {
+		s.Dashboards = len(dashboards[sig])
+		s.Tabs = len(tabKeys[sig])
+		s.FailingTabs = len(failingTabs[sig])
+		s.PassRate = -1
+		if s.Runs > 0 {
+			s.PassRate = float64(s.Passes) / float64(s.Runs)
+		}
+		slices.SortFunc(s.Offenders, func(a, b SIGTest) int {
+			return cmp.Or(
+				compareBool(b.Failing, a.Failing),
+				cmp.Compare(b.Consecutive, a.Consecutive),
+				cmp.Compare(b.Failures, a.Failures),
+				cmp.Compare(a.Dashboard, b.Dashboard),
+				cmp.Compare(a.Tab, b.Tab),
+				cmp.Compare(a.Test, b.Test),
+			)
+		})
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b SIGStats) int {
+		return cmp.Or(
+			cmp.Compare(b.Failing, a.Failing),
+			cmp.Compare(b.FailingTabs, a.FailingTabs),
+			cmp.Compare(b.Flaky, a.Flaky),
+			cmp.Compare(a.SIG, b.SIG),
+		)
+	})
+	return out
+}
+
+// ConsecutiveFailures counts the failures among the last window results
+// since the most recent pass, ignoring skipped and empty cells
+//
+// A test with consecutive failures is failing; one that failed within the
+// window but passed since is flaky.
+func ConsecutiveFailures(cells []client.Cell, window int) int {
+	n, runs := 0, 0
+	for _, c := range cells {
+		if window > 0 && runs == window {
+			break
+		}
+		switch c.Result {
+		case client.CellResultPass:
+			return n
+		case client.CellResultFail:
+			runs++
+			n++
+		}
+	}
+	return n
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+)
+
+// NoSkipReason groups skipped tests whose cells have no message
+const NoSkipReason = "(no reason)"
+
+// SkippedTest is a test that did not pass or fail within the window
+type SkippedTest struct {
+	Tab  string `json:"tab"`
+	Test string `json:"test"`
+	SIG  string `json:"sig"`
+	// Skips is the number of skipped cells in the window
+	Skips  int    `json:"skips"`
+	Reason string `json:"reason"`
+}
+
+// SkipReason is a skip message with the tests skipped for it
+type SkipReason struct {
+	Reason string        `json:"reason"`
+	Tests  []SkippedTest `json:"tests"`
+}
+
+// TabSkips are the totals of one tab
+type TabSkips struct {
+	Tab   string `json:"tab"`
+	Tests int    `json:"tests"`
+	// Skipped counts the tests in the tab that only skipped
+	Skipped int `json:"skipped"`
+}
+
+// SkipAudit lists the tests of a dashboard that only skipped
+type SkipAudit struct {
+	Dashboard string `json:"dashboard"`
+	// Window is the number of recent columns considered, 0 for all
+	Window  int          `json:"window"`
+	Tests   int          `json:"tests"`
+	Skipped int          `json:"skipped"`
+	Tabs    []TabSkips   `json:"tabs"`
+	Reasons []SkipReason `json:"reasons"`
+}
+
+// AuditSkipped finds the tests of tabs whose cells in the last window
+// columns are all skipped or empty, with at least one skip
+//
+// Tests are grouped by the first line of their most recent skip message.
+// Reasons are ordered by the number of tests, then alphabetically; tests
+// within a reason by tab and name. The Overall row is left out.
+func AuditSkipped(dashboard string, tabs []TabRows, window int) *SkipAudit {
+	audit := &SkipAudit{Dashboard: dashboard, Window: window, Tabs: []TabSkips{}, Reasons: []SkipReason{}}
+	byReason := make(map[string]*SkipReason)
+
+	for _, t := range tabs {
+		ts := TabSkips{Tab: t.Tab}
+		for _, r := range t.Rows {
+			if r.Name == client.OverallRow {
+				continue
+			}
+			ts.Tests++
+			s, ok := skippedTest(r.Cells, window)
+			if !ok {
+				continue
+			}
+			ts.Skipped++
+			s.Tab = t.Tab
+			s.Test = r.Name
+			s.SIG = testname.Parse(r.Name).SIGOrNone()
+
+			reason, ok := byReason[s.Reason]
+			if !ok {
+				reason = &SkipReason{Reason: s.Reason}
+				byReason[s.Reason] = reason
+			}
+			reason.Tests = append(reason.Tests, s)
+		}
+		audit.Tests += ts.Tests
+		audit.Skipped += ts.Skipped
+		audit.Tabs = append(audit.Tabs, ts)
+	}
+
+	for _, r := range byReason {
+		slices.SortFunc(r.Tests, func(a, b SkippedTest) int {
+			return cmp.Or(cmp.Compare(a.Tab, b.Tab), cmp.Compare(a.Test, b.Test))
+		})
+		audit.Reasons = append(audit.Reasons, *r)
+	}
+	slices.SortFunc(audit.Reasons, func(a, b SkipReason) int {
+		return cmp.Or(cmp.Compare(len(b.Tests), len(a.Tests)), cmp.Compare(a.Reason, b.Reason))
+	})
+	return audit
+}
+
+// skippedTest reports whether the first window cells hold skips and no
+// other results
+func skippedTest(cells []client.Cell, window int) (SkippedTest, bool) {
+	var s SkippedTest
+	for i, c := range cells {
+		if window > 0 && i == window {
+			break
+		}
+		switch c.Result {
+		case client.CellResultEmpty:
+		case client.CellResultSkipped:
+			if s.Skips == 0 {
+				line, _, _ := strings.Cut(c.Message, "\n")
+				s.Reason = strings.TrimSpace(line)
+			}
+			s.Skips++
+		default:
+			return s, false
+		}
+	}
+	if s.Reason == "" {
+		s.Reason = NoSkipReason
+	}
+	return s, s.Skips > 0
+}
@@ -0,0 +1,253 @@
+package analysis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Alert event kinds
+const (
+	AlertFired    = "fired"
+	AlertResolved = "resolved"
+	AlertStale    = "stale"
+)
+
+// StatusOptions are the TestGrid alerting parameters used to compute a status
+type StatusOptions struct {
+	// FailuresToAlert is the number of consecutive failures after which a
+	// test alerts and the tab is FAILING (num_failures_to_alert)
+	FailuresToAlert int `json:"failures_to_alert"`
+	// PassesToDisable is the number of consecutive passes after which an
+	// alerting test stops alerting (num_passes_to_disable_alert)
+	PassesToDisable int `json:"passes_to_disable"`
+	// RecentColumns is the number of recent columns in which any failure
+	// makes the tab FLAKY (num_columns_recent)
+	RecentColumns int `json:"recent_columns"`
+	// StaleHours marks the tab STALE when nothing ran for this long
+	// (alert_stale_results_hours); 0 disables it
+	StaleHours float64 `json:"stale_hours"`
+	// BrokenThreshold marks the tab BROKEN when at least this share of the
+	// tests in the latest column failed; 0 disables it
+	BrokenThreshold float64 `json:"broken_threshold"`
+}
+
+// DefaultStatusOptions match TestGrid's defaults for Kubernetes dashboards
+var DefaultStatusOptions = StatusOptions{
+	FailuresToAlert: 3,
+	PassesToDisable: 1,
+	RecentColumns:   10,
+	StaleHours:      24,
+}
+
+// AlertEvent is a test starting or stopping to alert, or the tab going stale
+type AlertEvent struct {
+	Kind    string `json:"kind"`
+	Column  int    `json:"column"`
+	Build   string `json:"build,omitempty"`
+	Started string `json:"started,omitempty"`
+	Test    string `json:"test,omitempty"`
+	// Failures is the number of consecutive failures when an alert fired
+	Failures int `json:"failures,omitempty"`
+}
+
+// ColumnStatus is the status the tab would have had right after a build
+type ColumnStatus struct {
+	Column   int    `json:"column"`
+	Build    string `json:"build,omitempty"`
+	Started  string `json:"started,omitempty"`
+	Status   string `json:"status"`
+	Alerting int    `json:"alerting"`
+}
+
+// Simulation is the outcome of replaying a tab's history under StatusOptions
+type Simulation struct {
+	Options StatusOptions `json:"options"`
+	// Status is the status now, and Reason explains it
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	// Alerting are the tests alerting after the latest build
+	Alerting []string `json:"alerting,omitempty"`
+	// Columns and Events are most recent first
+	Columns []ColumnStatus `json:"columns"`
+	Events  []AlertEvent   `json:"events"`
+}
+
+// testAlert tracks one test's alerting state while replaying history
+type testAlert struct {
+	failures int
+	passes   int
+	alerting bool
+}
+
+// Simulate replays a tab's columns from oldest to newest, computing the
+// status after each build with TestGrid-like rules:
+//
+//   - STALE if nothing ran for StaleHours (only for the current status)
+//   - PENDING if no recent column has results
+//   - FAILING if a test failed FailuresToAlert times in a row, until it
+//     passes PassesToDisable times in a row
+//   - BROKEN if BrokenThreshold of the latest column's tests failed
+//   - FLAKY if any test failed within RecentColumns
+//   - PASSING otherwise
+//
+// Empty and skipped cells neither extend nor break a streak.
+func Simulate(headers []client.Header, rows []client.Row, opts StatusOptions, now time.Time) *Simulation {
+	opts.FailuresToAlert = max(opts.FailuresToAlert, 1)
+	opts.PassesToDisable = max(opts.PassesToDisable, 1)
+	opts.RecentColumns = max(opts.RecentColumns, 1)
+	sim := &Simulation{Options: opts}
+
+	width := len(headers)
+	for _, r := range rows {
+		width = max(width, len(r.Cells))
+	}
+	header := func(col int) client.Header {
+		if col < len(headers) {
+			return headers[col]
+		}
+		return client.Header{}
+	}
+	staleAfter := time.Duration(opts.StaleHours * float64(time.Hour))
+
+	alerts := make([]testAlert, len(rows))
+	var events []AlertEvent
+	var columns []ColumnStatus
+	for col := width - 1; col >= 0; col-- {
+		h := header(col)
+		event := AlertEvent{Column: col, Build: h.Build, Started: h.Started}
+
+		if staleAfter > 0 && col+1 < width {
+			started, err1 := client.ParseTimestamp(h.Started)
+			prev, err2 := client.ParseTimestamp(header(col + 1).Started)
+			if err1 == nil && err2 == nil && started.Sub(prev) > staleAfter {
+				e := event
+				e.Kind = AlertStale
+				events = append(events, e)
+			}
+		}
+
+		for i, r := range rows {
+			if col >= len(r.Cells) {
+				continue
+			}
+			a := &alerts[i]
+			switch r.Cells[col].Result {
+			case client.CellResultFail:
+				a.failures++
+				a.passes = 0
+				if !a.alerting && a.failures >= opts.FailuresToAlert {
+					a.alerting = true
+					e := event
+					e.Kind, e.Test, e.Failures = AlertFired, r.Name, a.failures
+					events = append(events, e)
+				}
+			case client.CellResultPass:
+				a.passes++
+				a.failures = 0
+				if a.alerting && a.passes >= opts.PassesToDisable {
+					a.alerting = false
+					e := event
+					e.Kind, e.Test = AlertResolved, r.Name
+					events = append(events, e)
+				}
+			}
+		}
+
+		status, _ := columnStatus(rows, alerts, col, width, opts)
+		cs := ColumnStatus{Column: col, Build: h.Build, Started: h.Started, Status: status}
+		for _, a := range alerts {
+			if a.alerting {
+				cs.Alerting++
+			}
+		}
+		columns = append(columns, cs)
+	}
+
+	for i := len(columns) - 1; i >= 0; i-- {
+		sim.Columns = append(sim.Columns, columns[i])
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		sim.Events = append(sim.Events, events[i])
+	}
+	for i, a := range alerts {
+		if a.alerting {
+			sim.Alerting = append(sim.Alerting, rows[i].Name)
+		}
+	}
+
+	sim.Status, sim.Reason = columnStatus(rows, alerts, 0, width, opts)
+	if staleAfter > 0 {
+		if started, err := client.ParseTimestamp(header(0).Started); err == nil && now.Sub(started) > staleAfter {
+			sim.Status = "STALE"
+			sim.Reason = fmt.Sprintf("last run %s ago, stale after %s",
+				now.Sub(started).Round(time.Minute), staleAfter)
+		}
+	}
+	return sim
+}
+
+// columnStatus computes the status with col as the latest column, given
+// the alerting state after col
+func columnStatus(rows []client.Row, alerts []testAlert, col, width int, opts StatusOptions) (string, string) {
+	recent := min(col+opts.RecentColumns, width)
+	ran, failedColumns := 0, 0
+	for c := col; c < recent; c++ {
+		hasResult, failed := false, false
+		for _, r := range rows {
+			if c >= len(r.Cells) {
+				continue
+			}
+			switch r.Cells[c].Result {
+			case client.CellResultFail:
+				hasResult, failed = true, true
+			case client.CellResultPass:
+				hasResult = true
+			}
+		}
+		if hasResult {
+			ran++
+		}
+		if failed {
+			failedColumns++
+		}
+	}
+	if ran == 0 {
+		return "PENDING", "no results in recent columns"
+	}
+
+	alerting := 0
+	for _, a := range alerts {
+		if a.alerting {
+			alerting++
+		}
+	}
+	if alerting > 0 {
+		return "FAILING", fmt.Sprintf("%d tests failed at least %d times in a row", alerting, opts.FailuresToAlert)
+	}
+
+	if opts.BrokenThreshold > 0 {
+		tests, failed := 0, 0
+		for _, r := range rows {
+			if r.Name == client.OverallRow || col >= len(r.Cells) {
+				continue
+			}
+			switch r.Cells[col].Result {
+			case client.CellResultFail:
+				tests++
+				failed++
+			case client.CellResultPass:
+				tests++
+			}
+		}
+		if tests > 0 && float64(failed) >= opts.BrokenThreshold*float64(tests) {
+			return "BROKEN", fmt.Sprintf("%d of %d tests failed in the latest column", failed, tests)
+		}
+	}
+
+	if failedColumns > 0 {
+		return "FLAKY", fmt.Sprintf("%d of %d recent columns had failures, none alerting", failedColumns, ran)
+	}
+	return "PASSING", fmt.Sprintf("all %d recent columns passed", ran)
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// entry is a cached response body
+type entry struct {
+	key       string
+	body      []byte
+	fetchedAt time.Time
+}
+
+// Cache is a two-tier cache of response bodies: a bounded in-memory LRU backed by an
+// optional directory on disk
+//
+// Entries are never dropped on expiry so that stale data can still be served
+// when the upstream API is unavailable.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	dir        string
+}
+
+// New creates a cache holding at most maxEntries in memory, or any number
+// if maxEntries is not positive
+//
+// If dir is non-empty, entries are also persisted there and survive restarts.
+func New(maxEntries int, dir string) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		dir:        dir,
+	}
+}
+
+// Get returns the cached body for key and when it was fetched
+func (c *Cache) Get(key string) ([]byte, time.Time, bool) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		e := el.Value.(*entry)
+		c.mu.Unlock()
+		return e.body, e.fetchedAt, true
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil, time.Time{}, false
+	}
+
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	c.mu.Lock()
+	c.add(&entry{key: key, body: body, fetchedAt: info.ModTime()})
+	c.mu.Unlock()
+	return body, info.ModTime(), true
+}
+
+// Set stores body under key
+func (c *Cache) Set(key string, body []byte, fetchedAt time.Time) error {
+	c.mu.Lock()
+	c.add(&entry{key: key, body: body, fetchedAt: fetchedAt})
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), fetchedAt, fetchedAt); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Len returns the number of entries held in memory
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// add inserts or replaces an entry; c.mu must be held
+func (c *Cache) add(e *entry) {
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[e.key] = c.ll.PushFront(e)
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).key)
+	}
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Dir returns the directory for the named cache under the user's cache
+// directory, e.g. "http" for the proxy's responses
+func Dir(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "testgrid", name)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEviction(t *testing.T) {
+	c := New(2, "")
+	now := time.Now()
+	c.Set("a", []byte("1"), now)
+	c.Set("b", []byte("2"), now)
+	c.Get("a")
+	c.Set("c", []byte("3"), now)
+
+	if _, _, ok := c.Get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if _, _, ok := c.Get("a"); !ok {
+		t.Error("expected recently used entry to remain")
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	fetchedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := New(1, dir).Set("a", []byte("1"), fetchedAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, at, ok := New(1, dir).Get("a")
+	if !ok || string(body) != "1" || !at.Equal(fetchedAt) {
+		t.Errorf("expected entry from disk fetched at %v, got %q %v %v", fetchedAt, body, at, ok)
+	}
+}
@@ -57,32 +57,61 @@ func New(opts ...Option) *Client {
 	return c
 }
 
-// doRequest performs an HTTP request and decodes the JSON response
-func (c *Client) doRequest(ctx context.Context, path string, result interface{}) error {
+// APIError is returned when the API responds with a non-200 status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: status %d: %s", e.StatusCode, e.Body)
+}
+
+// GetRaw performs a GET request for path and returns the raw response body
+//
+// path must already be escaped; query may be nil.
+func (c *Client) GetRaw(ctx context.Context, path string, query url.Values) ([]byte, error) {
 	reqURL, err := url.JoinPath(c.baseURL, path)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
+		return nil, fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return body, nil
+}
+
+// doRequest performs an HTTP request and decodes the JSON response
+func (c *Client) doRequest(ctx context.Context, path string, result interface{}) error {
+	body, err := c.GetRaw(ctx, path, nil)
+	if err != nil {
+		return err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+	if err := json.Unmarshal(body, result); err != nil {
 		return fmt.Errorf("decoding response: %w", err)
 	}
 
@@ -3,8 +3,12 @@ package client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -12,9 +16,11 @@ import (
 type mockHTTPClient struct {
 	response *http.Response
 	err      error
+	request  *http.Request
 }
 
 func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	m.request = req
 	return m.response, m.err
 }
 
@@ -281,6 +287,34 @@ func TestAPIError(t *testing.T) {
 	if !bytes.Contains([]byte(err.Error()), []byte(expectedMsg)) {
 		t.Errorf("expected error to contain '%s', got '%s'", expectedMsg, err.Error())
 	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected APIError with status 404, got %v", err)
+	}
+}
+
+func TestGetRaw(t *testing.T) {
+	mock := &mockHTTPClient{
+		response: newMockResponse(http.StatusOK, `{"rows": []}`),
+	}
+
+	client := New(WithHTTPClient(mock))
+	query := url.Values{"foo": []string{"bar"}}
+	body, err := client.GetRaw(context.Background(), "/api/v1/dashboards/a%2Fb/tabs", query)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(body) != `{"rows": []}` {
+		t.Errorf("unexpected body '%s'", string(body))
+	}
+
+	expectedURL := DefaultBaseURL + "/api/v1/dashboards/a%2Fb/tabs?foo=bar"
+	if mock.request.URL.String() != expectedURL {
+		t.Errorf("expected URL '%s', got '%s'", expectedURL, mock.request.URL.String())
+	}
 }
 
 func TestWithBaseURL(t *testing.T) {
@@ -312,3 +346,182 @@ func TestCellResultString(t *testing.T) {
 		}
 	}
 }
+
+// routeHTTPClient serves canned bodies by request path and counts requests
+type routeHTTPClient struct {
+	routes   map[string]string
+	requests int
+}
+
+func (m *routeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	m.requests++
+	body, ok := m.routes[req.URL.Path]
+	if !ok {
+		return newMockResponse(http.StatusNotFound, "not found"), nil
+	}
+	return newMockResponse(http.StatusOK, body), nil
+}
+
+func TestMatch(t *testing.T) {
+	candidates := []Candidate{
+		{Name: "sig-release-master-blocking", Link: "/dashboards/sigreleasemasterblocking"},
+		{Name: "sig-release-master-informing", Link: "/dashboards/sigreleasemasterinforming"},
+		{Name: "sig-node-release-blocking", Link: "/dashboards/signodereleaseblocking"},
+	}
+
+	tests := []struct {
+		input     string
+		want      string
+		ambiguous bool
+		suggest   []string
+	}{
+		{input: "sig-release-master-blocking", want: "sig-release-master-blocking"},
+		{input: "SIG-Release-Master-Blocking", want: "sig-release-master-blocking"},
+		{input: "sigreleasemasterblocking", want: "sig-release-master-blocking"},
+		{input: "sig-node", want: "sig-node-release-blocking"},
+		{input: "signode", want: "sig-node-release-blocking"},
+		{input: "sig-release-master", ambiguous: true, suggest: []string{"sig-release-master-blocking", "sig-release-master-informing"}},
+		{input: "sig-relase-master-blocking", suggest: []string{"sig-release-master-blocking", "sig-release-master-informing"}},
+		{input: "something-else-entirely", suggest: nil},
+	}
+
+	for _, tt := range tests {
+		got, err := Match("dashboard", tt.input, candidates)
+		if tt.want != "" {
+			if err != nil || got != tt.want {
+				t.Errorf("Match(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+			}
+			continue
+		}
+
+		var resolveErr *ResolveError
+		if !errors.As(err, &resolveErr) {
+			t.Errorf("Match(%q): expected ResolveError, got %v", tt.input, err)
+			continue
+		}
+		if resolveErr.Ambiguous != tt.ambiguous || !slices.Equal(resolveErr.Suggestions, tt.suggest) {
+			t.Errorf("Match(%q): unexpected error %+v", tt.input, resolveErr)
+		}
+	}
+}
+
+func TestResolveErrorMessage(t *testing.T) {
+	err := &ResolveError{Kind: "tab", Input: "kind-mastr", Suggestions: []string{"kind-master"}}
+	if got := err.Error(); got != `tab "kind-mastr" not found; did you mean kind-master?` {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	mock := &routeHTTPClient{routes: map[string]string{
+		"/api/v1/dashboards":                                  `{"dashboards": [{"name": "sig-release-master-blocking", "link": "/dashboards/sigreleasemasterblocking"}]}`,
+		"/api/v1/dashboard-groups":                            `{"dashboard_groups": [{"name": "sig-release", "link": "/dashboard-groups/sigrelease"}]}`,
+		"/api/v1/dashboards/sig-release-master-blocking/tabs": `{"dashboard_tabs": [{"name": "gce-cos-master-default"}, {"name": "kind-master"}]}`,
+	}}
+	r := NewResolver(New(WithHTTPClient(mock)))
+	ctx := context.Background()
+
+	dashboard, tab, err := r.ResolveTab(ctx, "sigreleasemasterblocking", "KIND")
+	if err != nil || dashboard != "sig-release-master-blocking" || tab != "kind-master" {
+		t.Errorf("unexpected resolution: %q %q %v", dashboard, tab, err)
+	}
+
+	if _, _, err := r.ResolveTab(ctx, "sig-release-master-blocking", "kind-mastr"); err == nil || !strings.Contains(err.Error(), "did you mean kind-master?") {
+		t.Errorf("expected suggestion, got %v", err)
+	}
+	if mock.requests != 2 {
+		t.Errorf("expected lists to be cached, got %d requests", mock.requests)
+	}
+
+	if group, err := r.ResolveGroup(ctx, "SIG-RELEASE"); err != nil || group != "sig-release" {
+		t.Errorf("unexpected group: %q %v", group, err)
+	}
+}
+
+func TestResolverListError(t *testing.T) {
+	r := NewResolver(New(WithHTTPClient(&routeHTTPClient{})))
+
+	got, err := r.ResolveDashboard(context.Background(), "anything")
+	if err != nil || got != "anything" {
+		t.Errorf("expected input to pass through when listing fails, got %q %v", got, err)
+	}
+}
+
+func TestParseTabStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   string
+		want  TabStats
+		found bool
+	}{
+		{
+			name:  "passing with cells",
+			msg:   "Tab stats: 10 of 10 (100.0%) recent columns passed (19211 of 19211 or 100.0% cells)",
+			want:  TabStats{PassedColumns: 10, TotalColumns: 10, PassPercentage: 100, PassedCells: 19211, TotalCells: 19211, CellPassPercentage: 100},
+			found: true,
+		},
+		{
+			name:  "flaky with failed tests",
+			msg:   "Tab stats: 7 of 10 (70.0%) recent columns passed (1 tests failed)",
+			want:  TabStats{PassedColumns: 7, TotalColumns: 10, PassPercentage: 70, FailingTests: 1},
+			found: true,
+		},
+		{
+			name:  "failing with cells and failing tests",
+			msg:   "Tab stats: 0 of 9 (0.0%) recent columns passed (9120 of 9135 or 99.8% cells). 3 tests are failing",
+			want:  TabStats{TotalColumns: 9, PassedCells: 9120, TotalCells: 9135, CellPassPercentage: 99.8, FailingTests: 3},
+			found: true,
+		},
+		{
+			name:  "single failing test",
+			msg:   "Tab stats: 2 of 3 (66.7%) recent columns passed\n1 test is failing, 2 flaky tests, 1 broken column",
+			want:  TabStats{PassedColumns: 2, TotalColumns: 3, PassPercentage: 66.7, FailingTests: 1, FlakyTests: 2, BrokenColumns: 1},
+			found: true,
+		},
+		{
+			name:  "no results",
+			msg:   "No completed results",
+			want:  TabStats{NoResults: true},
+			found: true,
+		},
+		{name: "empty", msg: ""},
+		{name: "unrecognized", msg: "Tab was not updated recently"},
+	}
+
+	for _, tt := range tests {
+		got, found := ParseTabStats(tt.msg)
+		if found != tt.found || got != tt.want {
+			t.Errorf("%s: ParseTabStats(%q) = %+v, %v; want %+v, %v", tt.name, tt.msg, got, found, tt.want, tt.found)
+		}
+	}
+
+	summary := TabSummary{DetailedStatusMessage: "Tab stats: 1 of 2 (50.0%) recent columns passed"}
+	if s, ok := summary.Stats(); !ok || s.PassPercentage != 50 {
+		t.Errorf("unexpected stats from summary: %+v", s)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	got := TabURL(DefaultUIURL, "sig-release-master-blocking", "kind master", "[sig-node] Pods")
+	want := "https://testgrid.k8s.io/sig-release-master-blocking#kind+master&include-filter-by-regex=%5E%5C%5Bsig-node%5C%5D+Pods%24"
+	if got != want {
+		t.Errorf("TabURL:\n got  %s\n want %s", got, want)
+	}
+
+	got = ProwURL(DefaultProwURL+"/", DefaultGCSBucket, "ci-kubernetes-kind-e2e", "2016390463384694784")
+	want = "https://prow.k8s.io/view/gs/kubernetes-ci-logs/logs/ci-kubernetes-kind-e2e/2016390463384694784"
+	if got != want {
+		t.Errorf("ProwURL:\n got  %s\n want %s", got, want)
+	}
+	if ProwURL(DefaultProwURL, DefaultGCSBucket, "job", "") != "" {
+		t.Error("expected no Prow link without a build")
+	}
+}
+
+func TestCompareStatus(t *testing.T) {
+	statuses := []string{"PASSING", "UNKNOWN", "FLAKY", "FAILING", "STALE"}
+	slices.SortStableFunc(statuses, CompareStatus)
+	if want := []string{"FAILING", "FLAKY", "STALE", "PASSING", "UNKNOWN"}; !slices.Equal(statuses, want) {
+		t.Errorf("expected %v, got %v", want, statuses)
+	}
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Defaults for links to the web UIs
+const (
+	DefaultUIURL     = "https://testgrid.k8s.io"
+	DefaultProwURL   = "https://prow.k8s.io"
+	DefaultGCSBucket = "kubernetes-ci-logs"
+)
+
+// TabURL returns the TestGrid UI link to a tab, filtered to test if it is set
+func TabURL(base, dashboard, tab, test string) string {
+	u := strings.TrimSuffix(base, "/") + "/" + url.PathEscape(dashboard) + "#" + url.QueryEscape(tab)
+	if test != "" {
+		u += "&include-filter-by-regex=" + url.QueryEscape("^"+regexp.QuoteMeta(test)+"$")
+	}
+	return u
+}
+
+// ProwURL returns the Spyglass link to a build of a Prow job whose
+// artifacts are stored in bucket
+func ProwURL(base, bucket, job, build string) string {
+	if job == "" || build == "" {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/") + "/view/gs/" + bucket + "/logs/" + url.PathEscape(job) + "/" + url.PathEscape(build)
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxSuggestions is the number of "did you mean" suggestions in a ResolveError
+const maxSuggestions = 3
+
+// ResolveError is returned when a name does not match exactly one candidate
+type ResolveError struct {
+	// Kind is "dashboard", "group" or "tab"
+	Kind  string
+	Input string
+	// Ambiguous is set when Input is a prefix of several candidates,
+	// which are then listed in Suggestions
+	Ambiguous   bool
+	Suggestions []string
+}
+
+func (e *ResolveError) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("%s %q is ambiguous, it matches: %s", e.Kind, e.Input, strings.Join(e.Suggestions, ", "))
+	}
+	msg := fmt.Sprintf("%s %q not found", e.Kind, e.Input)
+	if len(e.Suggestions) > 0 {
+		msg += "; did you mean " + strings.Join(e.Suggestions, ", ") + "?"
+	}
+	return msg
+}
+
+// Candidate is a canonical name and its API link
+type Candidate struct {
+	Name string
+	Link string
+}
+
+// Slug normalizes a name the way TestGrid links do: lower case with
+// everything but letters and digits removed
+func Slug(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// Match maps input to the canonical name of one of candidates
+//
+// In order of preference input may be the exact name, the name in any case,
+// the link slug (e.g. "sigreleasemasterblocking"), or a prefix of exactly one
+// name or slug. Otherwise a *ResolveError with suggestions ranked by edit
+// distance is returned.
+func Match(kind, input string, candidates []Candidate) (string, error) {
+	for _, c := range candidates {
+		if c.Name == input {
+			return c.Name, nil
+		}
+	}
+
+	slug := Slug(input)
+	matchers := []func(Candidate) bool{
+		func(c Candidate) bool { return strings.EqualFold(c.Name, input) },
+		func(c Candidate) bool {
+			return slug != "" && (Slug(c.Name) == slug || (c.Link != "" && path.Base(c.Link) == input))
+		},
+		func(c Candidate) bool {
+			return strings.HasPrefix(strings.ToLower(c.Name), strings.ToLower(input)) ||
+				(slug != "" && strings.HasPrefix(Slug(c.Name), slug))
+		},
+	}
+	for _, m := range matchers {
+		var found []string
+		for _, c := range candidates {
+			if m(c) && !slices.Contains(found, c.Name) {
+				found = append(found, c.Name)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			slices.Sort(found)
+			return "", &ResolveError{Kind: kind, Input: input, Ambiguous: true, Suggestions: found}
+		}
+	}
+
+	return "", &ResolveError{Kind: kind, Input: input, Suggestions: suggest(input, candidates)}
+}
+
+// suggest returns the candidates closest to input by edit distance
+//
+// Candidates further than a third of the input's length are not worth suggesting.
+func suggest(input string, candidates []Candidate) []string {
+	type scored struct {
+		name string
+		dist int
+	}
+	limit := max(len(input)/3, 2)
+	lower := strings.ToLower(input)
+
+	var out []scored
+	for _, c := range candidates {
+		if d := levenshtein(lower, strings.ToLower(c.Name)); d <= limit {
+			out = append(out, scored{c.Name, d})
+		}
+	}
+	slices.SortFunc(out, func(a, b scored) int {
+		return cmp.Or(cmp.Compare(a.dist, b.dist), cmp.Compare(a.name, b.name))
+	})
+
+	var names []string
+	for _, s := range out[:min(len(out), maxSuggestions)] {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
+
+// Resolver maps user input to canonical dashboard, group and tab names
+// using the list endpoints, caching each list for the resolver's lifetime
+type Resolver struct {
+	client *Client
+
+	mu         sync.Mutex
+	dashboards []Candidate
+	groups     []Candidate
+	tabs       map[string][]Candidate
+}
+
+// NewResolver creates a Resolver backed by c
+func NewResolver(c *Client) *Resolver {
+	return &Resolver{client: c, tabs: make(map[string][]Candidate)}
+}
+
+// ResolveDashboard returns the canonical name of a dashboard
+//
+// If the dashboards cannot be listed the input is returned unchanged, so
+// that the request it is used for reports the actual API error.
+func (r *Resolver) ResolveDashboard(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dashboards == nil {
+		resp, err := r.client.ListDashboards(ctx)
+		if err != nil {
+			return name, nil
+		}
+		r.dashboards = make([]Candidate, 0, len(resp.Dashboards))
+		for _, d := range resp.Dashboards {
+			r.dashboards = append(r.dashboards, Candidate{Name: d.Name, Link: d.Link})
+		}
+	}
+	return Match("dashboard", name, r.dashboards)
+}
+
+// ResolveGroup returns the canonical name of a dashboard group
+func (r *Resolver) ResolveGroup(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.groups == nil {
+		resp, err := r.client.ListDashboardGroups(ctx)
+		if err != nil {
+			return name, nil
+		}
+		r.groups = make([]Candidate, 0, len(resp.DashboardGroups))
+		for _, g := range resp.DashboardGroups {
+			r.groups = append(r.groups, Candidate{Name: g.Name, Link: g.Link})
+		}
+	}
+	return Match("group", name, r.groups)
+}
+
+// ResolveTab returns the canonical names of a dashboard and one of its tabs
+func (r *Resolver) ResolveTab(ctx context.Context, dashboard, tab string) (string, string, error) {
+	dashboard, err := r.ResolveDashboard(ctx, dashboard)
+	if err != nil {
+		return "", "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tabs, ok := r.tabs[dashboard]
+	if !ok {
+		resp, err := r.client.ListDashboardTabs(ctx, dashboard)
+		if err != nil {
+			return dashboard, tab, nil
+		}
+		tabs = make([]Candidate, 0, len(resp.DashboardTabs))
+		for _, t := range resp.DashboardTabs {
+			tabs = append(tabs, Candidate{Name: t.Name, Link: t.Link})
+		}
+		r.tabs[dashboard] = tabs
+	}
+
+	tab, err = Match("tab", tab, tabs)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", dashboard, err)
+	}
+	return dashboard, tab, nil
+}
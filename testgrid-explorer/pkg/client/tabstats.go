@@ -0,0 +1,80 @@
+package client
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// TabStats are the statistics TestGrid embeds in DetailedStatusMessage
+type TabStats struct {
+	PassedColumns      int     `json:"passed_columns"`
+	TotalColumns       int     `json:"total_columns"`
+	PassPercentage     float64 `json:"pass_percentage"`
+	PassedCells        int     `json:"passed_cells,omitempty"`
+	TotalCells         int     `json:"total_cells,omitempty"`
+	CellPassPercentage float64 `json:"cell_pass_percentage,omitempty"`
+	FailingTests       int     `json:"failing_tests"`
+	FlakyTests         int     `json:"flaky_tests,omitempty"`
+	BrokenColumns      int     `json:"broken_columns,omitempty"`
+	// NoResults is set for tabs without completed columns
+	NoResults bool `json:"no_results,omitempty"`
+}
+
+var (
+	// "Tab stats: 7 of 10 (70.0%) recent columns passed"
+	columnsRe = regexp.MustCompile(`(\d+) of (\d+) \(([\d.]+)%\) recent columns passed`)
+	// "(19211 of 19211 or 100.0% cells)"
+	cellsRe = regexp.MustCompile(`(\d+) of (\d+) or ([\d.]+)% cells`)
+	// "1 tests failed", "3 tests failing", "1 test is failing"
+	failingTestsRe = regexp.MustCompile(`(\d+) tests? (?:(?:is|are) )?fail(?:ed|ing)`)
+	// "2 flaky tests", "2 tests are flaky"
+	flakyTestsRe = regexp.MustCompile(`(\d+) (?:flaky tests?|tests? (?:(?:is|are) )?flaky)`)
+	// "1 broken column", "3 broken columns"
+	brokenColumnsRe = regexp.MustCompile(`(\d+) broken columns?`)
+	noResultsRe     = regexp.MustCompile(`(?i)no (?:completed )?results`)
+)
+
+// ParseTabStats extracts statistics from a tab's detailed status message
+//
+// The second return value reports whether any statistic was recognized.
+// Fields that do not appear in the message are left zero.
+func ParseTabStats(msg string) (TabStats, bool) {
+	var s TabStats
+	found := false
+
+	if m := columnsRe.FindStringSubmatch(msg); m != nil {
+		s.PassedColumns, _ = strconv.Atoi(m[1])
+		s.TotalColumns, _ = strconv.Atoi(m[2])
+		s.PassPercentage, _ = strconv.ParseFloat(m[3], 64)
+		found = true
+	}
+	if m := cellsRe.FindStringSubmatch(msg); m != nil {
+		s.PassedCells, _ = strconv.Atoi(m[1])
+		s.TotalCells, _ = strconv.Atoi(m[2])
+		s.CellPassPercentage, _ = strconv.ParseFloat(m[3], 64)
+		found = true
+	}
+	for _, f := range []struct {
+		re  *regexp.Regexp
+		dst *int
+	}{
+		{failingTestsRe, &s.FailingTests},
+		{flakyTestsRe, &s.FlakyTests},
+		{brokenColumnsRe, &s.BrokenColumns},
+	} {
+		if m := f.re.FindStringSubmatch(msg); m != nil {
+			*f.dst, _ = strconv.Atoi(m[1])
+			found = true
+		}
+	}
+	if noResultsRe.MatchString(msg) {
+		s.NoResults = true
+		found = true
+	}
+	return s, found
+}
+
+// Stats parses the tab's DetailedStatusMessage
+func (t TabSummary) Stats() (TabStats, bool) {
+	return ParseTabStats(t.DetailedStatusMessage)
+}
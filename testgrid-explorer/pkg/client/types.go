@@ -1,6 +1,10 @@
 package client
 
-import "time"
+import (
+	"cmp"
+	"slices"
+	"time"
+)
 
 // Dashboard represents a single dashboard
 type Dashboard struct {
@@ -129,12 +133,30 @@ func CellResultString(result int) string {
 	}
 }
 
+// Statuses are the overall statuses of tabs and dashboards, from worst to best
+var Statuses = []string{"FAILING", "BROKEN", "FLAKY", "STALE", "PENDING", "ACCEPTABLE", "PASSING"}
+
+// CompareStatus orders statuses from worst to best, unknown statuses last
+func CompareStatus(a, b string) int {
+	rank := func(s string) int {
+		if i := slices.Index(Statuses, s); i >= 0 {
+			return i
+		}
+		return len(Statuses)
+	}
+	return cmp.Compare(rank(a), rank(b))
+}
+
 // Row represents a test row with its results
 type Row struct {
 	Name  string `json:"name"`
 	Cells []Cell `json:"cells"`
 }
 
+// OverallRow is the name of the synthetic per-build row TestGrid adds to
+// most tabs, whose cells reflect the result of the whole build
+const OverallRow = "Overall"
+
 // RowsResponse is the response from GET /api/v1/dashboards/{dashboard}/tabs/{tab}/rows
 type RowsResponse struct {
 	Rows []Row `json:"rows"`
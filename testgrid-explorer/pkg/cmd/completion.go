@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/cache"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionCacheTTL is how long completion candidates are reused
+	completionCacheTTL = 5 * time.Minute
+	// completionTimeout bounds API calls made while completing
+	completionTimeout = 5 * time.Second
+	// completionCacheMaxEntries bounds the candidate lists held in memory
+	completionCacheMaxEntries = 32
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a completion script for the given shell.
+
+Besides subcommands and flags, dashboard, group and tab names are completed
+from the TestGrid API. Results are cached for 5 minutes so completion stays
+fast.
+
+Bash:
+  # Load in the current shell (requires bash-completion)
+  source <(testgrid completion bash)
+  # Load for every session on Linux
+  testgrid completion bash > /etc/bash_completion.d/testgrid
+
+Zsh:
+  # Enable completion if not already done
+  echo "autoload -U compinit; compinit" >> ~/.zshrc
+  testgrid completion zsh > "${fpath[1]}/_testgrid"
+
+Fish:
+  testgrid completion fish > ~/.config/fish/completions/testgrid.fish
+
+PowerShell:
+  testgrid completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(out, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(out)
+		case "fish":
+			return cmd.Root().GenFishCompletion(out, true)
+		case "powershell":
+			return cmd.Root().GenPowerShellCompletionWithDesc(out)
+		default:
+			return fmt.Errorf("unsupported shell %q", args[0])
+		}
+	},
+}
+
+// completionCache holds candidate names on disk between completion runs
+var completionCache = cache.New(completionCacheMaxEntries, cache.Dir("completion"))
+
+// cachedNames returns the names cached under key, calling fetch when they are
+// missing or older than completionCacheTTL
+//
+// Stale names are better than none, so they are returned if fetch fails.
+func cachedNames(key string, fetch func(ctx context.Context, c *client.Client) ([]string, error)) []string {
+	// PersistentPreRun does not run for completion requests, so the client
+	// is created here from the already parsed global flags
+	key = baseURL + " " + key
+	body, fetchedAt, ok := completionCache.Get(key)
+	var names []string
+	if ok && json.Unmarshal(body, &names) == nil && time.Since(fetchedAt) < completionCacheTTL {
+		return names
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	fresh, err := fetch(ctx, newClient())
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("completing %s: %v", key, err), true)
+		return names
+	}
+	if body, err := json.Marshal(fresh); err == nil {
+		if err := completionCache.Set(key, body, time.Now()); err != nil {
+			cobra.CompDebugln(fmt.Sprintf("caching %s: %v", key, err), true)
+		}
+	}
+	return fresh
+}
+
+func dashboardNames() []string {
+	return cachedNames("dashboards", func(ctx context.Context, c *client.Client) ([]string, error) {
+		resp, err := c.ListDashboards(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(resp.Dashboards))
+		for _, d := range resp.Dashboards {
+			names = append(names, d.Name)
+		}
+		return names, nil
+	})
+}
+
+func groupNames() []string {
+	return cachedNames("groups", func(ctx context.Context, c *client.Client) ([]string, error) {
+		resp, err := c.ListDashboardGroups(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(resp.DashboardGroups))
+		for _, g := range resp.DashboardGroups {
+			names = append(names, g.Name)
+		}
+		return names, nil
+	})
+}
+
+func tabNames(dashboard string) []string {
+	return cachedNames("tabs/"+dashboard, func(ctx context.Context, c *client.Client) ([]string, error) {
+		resp, err := c.ListDashboardTabs(ctx, dashboard)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(resp.DashboardTabs))
+		for _, t := range resp.DashboardTabs {
+			names = append(names, t.Name)
+		}
+		return names, nil
+	})
+}
+
+// filterPrefix returns the names starting with prefix
+func filterPrefix(names []string, prefix string) []string {
+	var out []string
+	for _, n := range names {
+		if strings.HasPrefix(n, prefix) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// completeDashboard completes a single <dashboard> argument
+func completeDashboard(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(dashboardNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroup completes a single <group> argument
+func completeGroup(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(groupNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDashboardTab completes <dashboard> <tab>, scoping tabs to the
+// dashboard already typed
+func completeDashboardTab(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return filterPrefix(dashboardNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return filterPrefix(tabNames(args[0]), toComplete), cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeDashboardTabs completes a dashboard followed by any number of its
+// tabs, leaving out tabs already given
+func completeDashboardTabs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return filterPrefix(dashboardNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, t := range filterPrefix(tabNames(args[0]), toComplete) {
+		if !slices.Contains(args[1:], t) {
+			out = append(out, t)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTarget completes dashboard[/tab] watch targets
+func completeTarget(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dashboard, tab, found := strings.Cut(toComplete, "/")
+	if !found {
+		var out []string
+		for _, d := range filterPrefix(dashboardNames(), dashboard) {
+			out = append(out, d+"/")
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	if strings.Contains(tab, "/") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, t := range filterPrefix(tabNames(dashboard), tab) {
+		out = append(out, dashboard+"/"+t)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDashboardFlag completes --dashboard flags
+func completeDashboardFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterPrefix(dashboardNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroupFlag completes --group flags
+func completeGroupFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterPrefix(groupNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerNameFlagCompletion completes the --group and --dashboard flags of cmd
+func registerNameFlagCompletion(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("group") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("group", completeGroupFlag)
+	}
+	if cmd.Flags().Lookup("dashboard") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("dashboard", completeDashboardFlag)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	correlateBuilds      int
+	correlateByTime      bool
+	correlateTimeWindow  time.Duration
+	correlateMinTabs     int
+	correlateLabels      []string
+	correlateConcurrency int
+)
+
+var correlateCmd = &cobra.Command{
+	Use:   "correlate <dashboard>",
+	Short: "Tell widespread test failures from tab-specific ones",
+	Long: `Align the recent builds of every tab in a dashboard by the commit they
+tested and label each test failure:
+
+  widespread  the test failed in --min-tabs or more tabs at the same commit,
+              almost certainly a real regression
+  localized   the test failed in one tab but passed in another at the same
+              commit, likely specific to that tab's environment
+  unaligned   no other tab ran the test at that commit, so nothing can be
+              told
+
+The commit is the first extra of a column header. Columns without one, or
+every column with --by-time, are aligned by start time in buckets of
+--time-window instead. Each test is labeled by its most common kind of
+failure; the co-failure rate is the share of tabs that ran the test and
+failed, averaged over its failures.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
+	Example: `  # Which failures are real regressions?
+  testgrid correlate sig-release-master-blocking
+
+  # Only environment-specific failures
+  testgrid correlate sig-release-master-blocking --label=localized
+
+  # Align jobs that do not report commits by 6 hour windows
+  testgrid correlate sig-node-release-blocking --by-time --time-window=6h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		for _, l := range correlateLabels {
+			if !slices.Contains([]string{analysis.Widespread, analysis.Localized, analysis.Unaligned}, l) {
+				return fmt.Errorf("unknown --label %q (supported: widespread, localized, unaligned)", l)
+			}
+		}
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		tabs, tabErrs, err := analysis.FetchDashboardGrids(ctx, apiClient, dashboard, correlateConcurrency)
+		if err != nil {
+			return err
+		}
+		for _, e := range tabErrs {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+		}
+		for i := range tabs {
+			tabs[i].Rows = filterRowsByName(excludeInfra(tabs[i].Rows))
+		}
+
+		c := analysis.Correlate(dashboard, tabs, analysis.CorrelateOptions{
+			Builds:     correlateBuilds,
+			ByTime:     correlateByTime,
+			TimeWindow: correlateTimeWindow,
+			MinTabs:    correlateMinTabs,
+		})
+		if len(correlateLabels) > 0 {
+			c.Tests = slices.DeleteFunc(c.Tests, func(t analysis.TestCorrelation) bool {
+				return !slices.Contains(correlateLabels, t.Label)
+			})
+		}
+
+		return formatter.Print(c, func(w io.Writer) error {
+			fmt.Fprintf(w, "%d tabs, %d builds shared by two or more tabs\n\n", len(c.Tabs), c.Shared)
+			if len(c.Tests) == 0 {
+				fmt.Fprintln(w, "No failing tests")
+				return nil
+			}
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "LABEL", "TEST", "FAILURES", "WIDESPREAD", "LOCALIZED", "CO-FAILURE", "TABS")
+			for _, t := range c.Tests {
+				rate := "-"
+				if t.CoFailureRate >= 0 {
+					rate = fmt.Sprintf("%.0f%%", t.CoFailureRate*100)
+				}
+				output.PrintRow(tw, correlationLabel(t.Label), output.TruncateString(t.Test, 80), fmt.Sprint(t.Failures),
+					fmt.Sprint(t.Widespread), fmt.Sprint(t.Localized), rate, strings.Join(t.Tabs, ", "))
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+// correlationLabel colors a failure label like the status it suggests
+func correlationLabel(label string) string {
+	switch label {
+	case analysis.Widespread:
+		return output.StatusColor("FAILING") + label + output.ResetColor()
+	case analysis.Localized:
+		return output.StatusColor("FLAKY") + label + output.ResetColor()
+	default:
+		return label
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(correlateCmd)
+
+	correlateCmd.Flags().IntVar(&correlateBuilds, "builds", analysis.DefaultCorrelateOptions.Builds, "Number of recent columns considered per tab (0 for all)")
+	correlateCmd.Flags().BoolVar(&correlateByTime, "by-time", false, "Align every column by start time instead of commit")
+	correlateCmd.Flags().DurationVar(&correlateTimeWindow, "time-window", analysis.DefaultCorrelateOptions.TimeWindow, "Bucket size for aligning columns by start time")
+	correlateCmd.Flags().IntVar(&correlateMinTabs, "min-tabs", analysis.DefaultCorrelateOptions.MinTabs, "Failing tabs at the same commit that make a failure widespread")
+	correlateCmd.Flags().StringSliceVar(&correlateLabels, "label", nil, "Only show tests with these labels: widespread, localized, unaligned")
+	correlateCmd.Flags().IntVar(&correlateConcurrency, "concurrency", analysis.DefaultConcurrency, "Maximum number of tabs fetched at once")
+	addTestFilterFlags(correlateCmd)
+	addInfraFlag(correlateCmd)
+}
@@ -2,13 +2,26 @@ package cmd
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
 	"github.com/sozercan/testgrid-explorer/pkg/output"
 	"github.com/spf13/cobra"
 )
 
+var (
+	matrixBuilds         int
+	matrixPassRate       bool
+	matrixMinFailingTabs int
+	matrixCSV            bool
+	matrixConcurrency    int
+)
+
 var dashboardsCmd = &cobra.Command{
 	Use:     "dashboards",
 	Aliases: []string{"dash", "d"},
@@ -44,15 +57,19 @@ var dashboardsListCmd = &cobra.Command{
 }
 
 var dashboardsGetCmd = &cobra.Command{
-	Use:   "get <dashboard>",
-	Short: "Get dashboard configuration",
-	Long:  "Get the configuration for a specific dashboard.",
-	Args:  cobra.ExactArgs(1),
+	Use:               "get <dashboard>",
+	Short:             "Get dashboard configuration",
+	Long:              "Get the configuration for a specific dashboard.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
 	Example: `  # Get dashboard configuration
   testgrid dashboards get sig-release-master-blocking`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetDashboardConfig(ctx, dashboard)
 		if err != nil {
@@ -75,15 +92,19 @@ var dashboardsGetCmd = &cobra.Command{
 }
 
 var dashboardsSummaryCmd = &cobra.Command{
-	Use:   "summary <dashboard>",
-	Short: "Get dashboard summary",
-	Long:  "Get the summary and health status for a specific dashboard.",
-	Args:  cobra.ExactArgs(1),
+	Use:               "summary <dashboard>",
+	Short:             "Get dashboard summary",
+	Long:              "Get the summary and health status for a specific dashboard.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
 	Example: `  # Get dashboard summary
   testgrid dashboards summary sig-release-master-blocking`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetDashboardSummary(ctx, dashboard)
 		if err != nil {
@@ -109,9 +130,155 @@ var dashboardsSummaryCmd = &cobra.Command{
 	},
 }
 
+var dashboardsMatrixCmd = &cobra.Command{
+	Use:   "matrix <dashboard>",
+	Short: "Show tests by tabs for a dashboard",
+	Long: `Fetch the rows of every tab in a dashboard and show each test's latest
+result in every tab, to tell tests failing everywhere from tests failing on
+a single job or provider.
+
+Tests are ordered by the number of tabs whose latest result failed. With
+--pass-rate, cells show the pass rate over the last --builds results instead.
+Tabs are numbered in the table; the legend maps numbers to tab names.
+
+Build columns in which most tests failed or did not run are treated as
+infrastructure failures and left out unless --include-infra-failures is set.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
+	Example: `  # Show the matrix for a dashboard
+  testgrid dashboards matrix sig-release-master-blocking
+
+  # Tests failing in at least 3 tabs
+  testgrid dashboards matrix sig-release-master-blocking --min-failing-tabs=3
+
+  # Only SIG Network tests
+  testgrid dashboards matrix sig-release-master-blocking --sig=network
+
+  # Pass rates over the last 20 builds as CSV
+  testgrid dashboards matrix sig-release-master-informing --pass-rate --builds=20 --csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		tabs, tabErrs, err := analysis.FetchDashboardRows(ctx, apiClient, dashboard, matrixConcurrency)
+		if err != nil {
+			return err
+		}
+		for _, e := range tabErrs {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+		}
+
+		for i := range tabs {
+			tabs[i].Rows = filterRowsByName(excludeInfra(tabs[i].Rows))
+		}
+		m := analysis.BuildMatrix(dashboard, tabs, matrixBuilds)
+		if matrixMinFailingTabs > 0 {
+			m = m.FilterMinFailing(matrixMinFailingTabs)
+		}
+
+		if matrixCSV {
+			return writeMatrixCSV(os.Stdout, m, matrixPassRate)
+		}
+
+		return formatter.Print(m, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			header := []string{"TEST"}
+			for i := range m.Tabs {
+				header = append(header, fmt.Sprintf("[%d]", i+1))
+			}
+			header = append(header, "FAILING")
+			output.PrintRow(tw, header...)
+
+			for _, r := range m.Rows {
+				row := []string{output.TruncateString(r.Test, 80)}
+				for _, c := range r.Cells {
+					row = append(row, formatMatrixCell(c, matrixPassRate))
+				}
+				row = append(row, fmt.Sprintf("%d/%d", r.FailingTabs, len(m.Tabs)))
+				output.PrintRow(tw, row...)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w)
+			for i, t := range m.Tabs {
+				fmt.Fprintf(w, "[%d] %s\n", i+1, t)
+			}
+			fmt.Fprintf(w, "\nShowing %d tests across %d tabs\n", len(m.Rows), len(m.Tabs))
+			return nil
+		})
+	},
+}
+
+// formatMatrixCell renders a cell as a result symbol or a pass rate
+func formatMatrixCell(c *analysis.MatrixCell, passRate bool) string {
+	switch {
+	case c == nil:
+		return " "
+	case passRate && c.PassRate >= 0:
+		pct := fmt.Sprintf("%.0f%%", c.PassRate*100)
+		switch {
+		case c.PassRate == 1:
+			return "\033[32m" + pct + "\033[0m"
+		case c.PassRate == 0:
+			return "\033[31m" + pct + "\033[0m"
+		default:
+			return "\033[33m" + pct + "\033[0m"
+		}
+	case passRate:
+		return "-"
+	default:
+		return formatCellResults([]client.Cell{{Result: c.Result}}, 1)
+	}
+}
+
+// writeMatrixCSV writes one row per test and one column per tab
+func writeMatrixCSV(w io.Writer, m *analysis.Matrix, passRate bool) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{"test"}, m.Tabs...)
+	header = append(header, "failing_tabs")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range m.Rows {
+		record := []string{r.Test}
+		for _, c := range r.Cells {
+			switch {
+			case c == nil:
+				record = append(record, "")
+			case passRate && c.PassRate >= 0:
+				record = append(record, strconv.FormatFloat(c.PassRate, 'f', 3, 64))
+			case passRate:
+				record = append(record, "")
+			default:
+				record = append(record, c.Latest)
+			}
+		}
+		record = append(record, strconv.Itoa(r.FailingTabs))
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 func init() {
 	rootCmd.AddCommand(dashboardsCmd)
 	dashboardsCmd.AddCommand(dashboardsListCmd)
 	dashboardsCmd.AddCommand(dashboardsGetCmd)
 	dashboardsCmd.AddCommand(dashboardsSummaryCmd)
+	dashboardsCmd.AddCommand(dashboardsMatrixCmd)
+
+	dashboardsMatrixCmd.Flags().IntVar(&matrixBuilds, "builds", 10, "Number of recent results used for pass rates")
+	dashboardsMatrixCmd.Flags().BoolVar(&matrixPassRate, "pass-rate", false, "Show pass rates instead of latest results")
+	dashboardsMatrixCmd.Flags().IntVar(&matrixMinFailingTabs, "min-failing-tabs", 0, "Only show tests failing in at least this many tabs")
+	dashboardsMatrixCmd.Flags().BoolVar(&matrixCSV, "csv", false, "Write the matrix as CSV")
+	dashboardsMatrixCmd.Flags().IntVar(&matrixConcurrency, "concurrency", analysis.DefaultConcurrency, "Maximum number of tabs fetched at once")
+	addTestFilterFlags(dashboardsMatrixCmd)
+	addInfraFlag(dashboardsMatrixCmd)
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sozercan/testgrid-explorer/pkg/export"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportGroups        []string
+	exportDashboards    []string
+	exportConcurrency   int
+	exportSummariesOnly bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export grids to other formats",
+	Long:  "Commands for exporting TestGrid data for offline analysis.",
+}
+
+var exportSQLiteCmd = &cobra.Command{
+	Use:   "sqlite <file>",
+	Short: "Export grids to a SQLite database",
+	Long: `Crawl the selected groups and dashboards and write them to a SQLite
+database with the tables:
+
+  groups, dashboards, tabs, tab_summaries, builds, tests, cells, results
+
+The database is created if it does not exist. Rows are upserted by their
+natural keys, so running the export again adds new builds, tests and
+summaries to the same file. Empty cells (tests that did not run) are not
+stored.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  # Export a group
+  testgrid export sqlite testgrid.db --group=sig-release
+
+  # Extend the database with another dashboard
+  testgrid export sqlite testgrid.db --dashboard=sig-node-release-blocking
+
+  # Query failures per test
+  sqlite3 testgrid.db "SELECT t.name, COUNT(*) FROM cells c JOIN tests t ON t.id = c.test_id WHERE c.result = 2 GROUP BY t.name ORDER BY 2 DESC LIMIT 10"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(exportGroups) == 0 && len(exportDashboards) == 0 {
+			return fmt.Errorf("at least one --group or --dashboard is required")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		groups, err := resolveGroups(ctx, exportGroups)
+		if err != nil {
+			return err
+		}
+		dashboards, err := resolveDashboards(ctx, exportDashboards)
+		if err != nil {
+			return err
+		}
+
+		store, err := export.Open(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		stats, crawlErr := export.Crawl(ctx, apiClient, store, export.CrawlOptions{
+			Groups:        groups,
+			Dashboards:    dashboards,
+			Concurrency:   exportConcurrency,
+			SummariesOnly: exportSummariesOnly,
+		})
+		if crawlErr != nil {
+			fmt.Fprintf(os.Stderr, "export errors: %v\n", crawlErr)
+		}
+
+		err = formatter.Print(stats, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "GROUPS", "DASHBOARDS", "TABS", "BUILDS", "TESTS", "CELLS", "ERRORS")
+			output.PrintRow(tw, fmt.Sprint(stats.Groups), fmt.Sprint(stats.Dashboards), fmt.Sprint(stats.Tabs),
+				fmt.Sprint(stats.Builds), fmt.Sprint(stats.Tests), fmt.Sprint(stats.Cells), fmt.Sprint(stats.Errors))
+			return tw.Flush()
+		})
+		if err != nil {
+			return err
+		}
+		if stats.Tabs == 0 && crawlErr != nil {
+			return fmt.Errorf("nothing was exported")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportSQLiteCmd)
+
+	exportSQLiteCmd.Flags().StringArrayVar(&exportGroups, "group", nil, "Dashboard group to export (repeatable)")
+	exportSQLiteCmd.Flags().StringArrayVar(&exportDashboards, "dashboard", nil, "Dashboard to export (repeatable)")
+	exportSQLiteCmd.Flags().IntVar(&exportConcurrency, "concurrency", export.DefaultConcurrency, "Maximum number of tabs fetched at once")
+	exportSQLiteCmd.Flags().BoolVar(&exportSummariesOnly, "summaries-only", false, "Only export tab summaries, skipping builds, tests and cells")
+
+	registerNameFlagCompletion(exportSQLiteCmd)
+}
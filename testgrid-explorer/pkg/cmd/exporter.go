@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/exporter"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exporterAddr        string
+	exporterInterval    time.Duration
+	exporterGroups      []string
+	exporterDashboards  []string
+	exporterTextfile    string
+	exporterConcurrency int
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Export dashboard and tab health as Prometheus metrics",
+	Long: `Periodically collect the configured groups and dashboards and expose
+their health on /metrics in the Prometheus text format.
+
+Exported metrics include:
+  testgrid_tab_status{dashboard,tab,status}
+  testgrid_tab_last_run_timestamp_seconds{dashboard,tab}
+  testgrid_tab_pass_percentage{dashboard,tab}
+  testgrid_dashboard_status{dashboard,status}
+  testgrid_dashboard_tab_status_count{dashboard,status}
+  testgrid_exporter_collect_success{dashboard}
+  testgrid_client_requests_total{code}, testgrid_client_errors_total
+
+A dashboard that cannot be fetched keeps the values of its last successful
+collection, and its testgrid_exporter_collect_success drops to 0.
+
+With --textfile, metrics are collected once and written to a file for
+node_exporter's textfile collector instead of being served.`,
+	Example: `  # Serve metrics for the sig-release group
+  testgrid exporter --group=sig-release
+
+  # Collect two dashboards every 10 minutes
+  testgrid exporter --dashboard=sig-release-master-blocking --dashboard=sig-release-master-informing --interval=10m
+
+  # Write a textfile for node_exporter from cron
+  testgrid exporter --group=sig-release --textfile=/var/lib/node_exporter/textfile/testgrid.prom`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(exporterGroups) == 0 && len(exporterDashboards) == 0 {
+			return fmt.Errorf("at least one --group or --dashboard is required")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		groups, err := resolveGroups(ctx, exporterGroups)
+		if err != nil {
+			return err
+		}
+		dashboards, err := resolveDashboards(ctx, exporterDashboards)
+		if err != nil {
+			return err
+		}
+
+		registry := metrics.NewRegistry()
+		hc := metrics.InstrumentHTTPClient(&http.Client{Timeout: client.DefaultTimeout}, registry)
+		exp := exporter.New(newClient(client.WithHTTPClient(hc)), registry,
+			exporter.WithGroups(groups...),
+			exporter.WithDashboards(dashboards...),
+			exporter.WithConcurrency(exporterConcurrency),
+		)
+
+		if exporterTextfile != "" {
+			collectErr := exp.Collect(ctx)
+			if collectErr != nil {
+				fmt.Fprintf(os.Stderr, "collection errors: %v\n", collectErr)
+			}
+			return exporter.WriteTextfile(registry, exporterTextfile)
+		}
+
+		if exporterInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		go func() {
+			ticker := time.NewTicker(exporterInterval)
+			defer ticker.Stop()
+			for {
+				if err := exp.Collect(ctx); err != nil && ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "collection errors: %v\n", err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", registry.Handler())
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintln(w, `<html><body><a href="/metrics">Metrics</a></body></html>`)
+		})
+		return listenAndServe(ctx, exporterAddr, mux)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().StringVar(&exporterAddr, "addr", ":9799", "Address to serve /metrics on")
+	exporterCmd.Flags().DurationVar(&exporterInterval, "interval", 5*time.Minute, "Collection interval")
+	exporterCmd.Flags().StringArrayVar(&exporterGroups, "group", nil, "Dashboard group to collect (repeatable)")
+	exporterCmd.Flags().StringArrayVar(&exporterDashboards, "dashboard", nil, "Dashboard to collect (repeatable)")
+	exporterCmd.Flags().StringVar(&exporterTextfile, "textfile", "", "Collect once and write metrics to this file instead of serving")
+	exporterCmd.Flags().IntVar(&exporterConcurrency, "concurrency", exporter.DefaultConcurrency, "Maximum number of dashboards collected at once")
+
+	registerNameFlagCompletion(exporterCmd)
+}
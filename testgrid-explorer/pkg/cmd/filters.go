@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sigFilter        []string
+	tagFilter        []string
+	excludeTagFilter []string
+
+	includeInfraFailures bool
+)
+
+// addTestFilterFlags registers --sig, --tag and --exclude-tag on a grid command
+func addTestFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&sigFilter, "sig", nil, "Only include tests owned by these SIGs, e.g. storage or sig-node")
+	cmd.Flags().StringSliceVar(&tagFilter, "tag", nil, "Only include tests with all of these tags, e.g. Conformance or Feature:X")
+	cmd.Flags().StringSliceVar(&excludeTagFilter, "exclude-tag", nil, "Exclude tests with any of these tags, e.g. Flaky")
+}
+
+// testFilter returns the filter selected by the test filter flags
+func testFilter() testname.Filter {
+	return testname.Filter{SIGs: sigFilter, Tags: tagFilter, ExcludeTags: excludeTagFilter}
+}
+
+// filterRowsByName keeps the rows whose test names pass the test filter flags
+func filterRowsByName(rows []client.Row) []client.Row {
+	f := testFilter()
+	if f.Empty() {
+		return rows
+	}
+	var out []client.Row
+	for _, r := range rows {
+		if f.Match(r.Name) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// addInfraFlag registers --include-infra-failures on a command that computes
+// pass rates, flakiness or regressions
+func addInfraFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&includeInfraFailures, "include-infra-failures", false,
+		"Count build columns that look like infrastructure failures (most tests failed or did not run)")
+}
+
+// excludeInfra masks infra failure columns unless --include-infra-failures is set
+func excludeInfra(rows []client.Row) []client.Row {
+	if includeInfraFailures {
+		return rows
+	}
+	return analysis.ExcludeInfraFailures(rows)
+}
+
+// printSIGStats prints per-SIG aggregates as a table or JSON
+func printSIGStats(stats []analysis.SIGStats) error {
+	data := struct {
+		SIGs []analysis.SIGStats `json:"sigs"`
+	}{stats}
+	return formatter.Print(data, func(w io.Writer) error {
+		tw := output.TableWriter(w)
+		output.PrintRow(tw, "SIG", "TESTS", "FAILING", "FLAKY", "PASS RATE")
+		for _, s := range stats {
+			rate := "-"
+			if s.PassRate >= 0 {
+				rate = fmt.Sprintf("%.1f%%", s.PassRate*100)
+			}
+			output.PrintRow(tw, s.SIG, fmt.Sprint(s.Tests), fmt.Sprint(s.Failing), fmt.Sprint(s.Flaky), rate)
+		}
+		return tw.Flush()
+	})
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/gate"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the gate command
+const (
+	exitViolated      = 2
+	exitIndeterminate = 3
+)
+
+var gatePolicy string
+
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Evaluate a health policy for CI gating",
+	Long: `Evaluate a declarative policy file against live TestGrid data and exit
+with a code that reflects the verdict:
+
+  0  every rule passed
+  1  the command failed (bad flags, unreadable policy, ...)
+  2  at least one rule was violated
+  3  no rule was violated but at least one could not be evaluated
+
+Policies are JSON files with a list of rules:
+
+  {
+    "rules": [
+      {"type": "tab_status", "dashboard": "sig-release-master-blocking",
+       "allowed_statuses": ["PASSING", "FLAKY"]},
+      {"type": "test_passed", "dashboard": "sig-release-master-blocking",
+       "tab": "kind-master", "test": "ci-kubernetes-e2e-kind.Overall", "builds": 3},
+      {"type": "max_staleness", "group": "sig-release", "max_age": "24h"}
+    ]
+  }
+
+Tab rules take either "dashboard" or "group" and may be restricted with
+"tabs". Every rule accepts an optional "name".`,
+	Example: `  # Gate a promotion on the release-blocking policy
+  testgrid gate --policy release-blocking.json
+
+  # Emit the report as JSON for pipeline consumption
+  testgrid gate --policy release-blocking.json -o json`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if gatePolicy == "" {
+			return fmt.Errorf("--policy is required")
+		}
+		policy, err := gate.LoadPolicy(gatePolicy)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		report := gate.Evaluate(ctx, apiClient, policy, time.Now().UTC())
+		err = formatter.Print(report, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "RULE", "TYPE", "VERDICT", "CHECKED", "DETAILS")
+			for _, r := range report.Rules {
+				details := strings.Join(r.Violations, "; ")
+				if r.Error != "" {
+					details = r.Error
+				}
+				output.PrintRow(tw, output.TruncateString(r.Name, 60), string(r.Type), output.ColorStatus(string(r.Verdict)),
+					fmt.Sprint(r.Checked), output.TruncateString(details, 100))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\nVerdict: %s\n", output.ColorStatus(string(report.Verdict)))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		switch report.Verdict {
+		case gate.VerdictViolated:
+			return &exitError{code: exitViolated}
+		case gate.VerdictIndeterminate:
+			return &exitError{code: exitIndeterminate}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gateCmd)
+
+	gateCmd.Flags().StringVar(&gatePolicy, "policy", "", "Path to the JSON policy file")
+}
@@ -44,15 +44,19 @@ var groupsListCmd = &cobra.Command{
 }
 
 var groupsGetCmd = &cobra.Command{
-	Use:   "get <group>",
-	Short: "Get dashboards in a group",
-	Long:  "List all dashboards belonging to a specific group.",
-	Args:  cobra.ExactArgs(1),
+	Use:               "get <group>",
+	Short:             "Get dashboards in a group",
+	Long:              "List all dashboards belonging to a specific group.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroup,
 	Example: `  # List dashboards in sig-release group
   testgrid groups get sig-release`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		group := args[0]
+		group, err := resolver.ResolveGroup(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetGroupDashboards(ctx, group)
 		if err != nil {
@@ -71,15 +75,19 @@ var groupsGetCmd = &cobra.Command{
 }
 
 var groupsSummariesCmd = &cobra.Command{
-	Use:   "summaries <group>",
-	Short: "Get dashboard summaries for a group",
-	Long:  "Get health summaries for all dashboards in a group.",
-	Args:  cobra.ExactArgs(1),
+	Use:               "summaries <group>",
+	Short:             "Get dashboard summaries for a group",
+	Long:              "Get health summaries for all dashboards in a group.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroup,
 	Example: `  # Get summaries for sig-release group
   testgrid groups summaries sig-release`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		group := args[0]
+		group, err := resolver.ResolveGroup(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetGroupDashboardSummaries(ctx, group)
 		if err != nil {
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/sozercan/testgrid-explorer/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseMinors      int
+	releaseConcurrency int
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Inspect Kubernetes release branches",
+	Long:  "Commands for following the sig-release dashboards of each release branch.",
+}
+
+var releaseStatusCmd = &cobra.Command{
+	Use:   "status [version]",
+	Short: "Show the readiness of release branches",
+	Long: `Find the sig-release-<version>-blocking and -informing dashboards and
+summarize each release branch in one view: the status of its blocking and
+informing dashboards, their tabs by status, and whether every blocking tab
+is passing.
+
+Without a version, master and the --minors most recent minor releases are
+shown. Blocking tabs that are not passing are listed below the overview.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeReleaseVersion,
+	Example: `  # Master and the supported minor releases
+  testgrid release status
+
+  # A single branch
+  testgrid release status 1.34
+
+  # As JSON for a release dashboard
+  testgrid release status -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		opts := report.ReleaseOptions{Minors: releaseMinors, Concurrency: releaseConcurrency}
+		if len(args) > 0 {
+			opts.Version = args[0]
+		}
+		data, err := report.GatherRelease(ctx, apiClient, opts)
+		if err != nil {
+			return err
+		}
+		for _, e := range data.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		return formatter.Print(data, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "BRANCH", "READY", "BLOCKING", "BLOCKING TABS", "INFORMING", "INFORMING TABS")
+			for _, b := range data.Branches {
+				ready := output.StatusColor("FAILING") + "no" + output.ResetColor()
+				if b.Ready {
+					ready = output.StatusColor("PASSING") + "yes" + output.ResetColor()
+				}
+				informing, informingTabs := "-", "-"
+				if b.Informing != nil {
+					informing = output.ColorStatus(b.Informing.OverallStatus)
+					informingTabs = tabStatusCounts(b.Informing.Tabs)
+				}
+				blockingTabs := "-"
+				if b.Blocking != nil {
+					blockingTabs = tabStatusCounts(b.Blocking.Tabs)
+				}
+				output.PrintRow(tw, b.Version, ready, output.ColorStatus(b.Status), blockingTabs, informing, informingTabs)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			for _, b := range data.Branches {
+				if len(b.NotPassing) == 0 {
+					continue
+				}
+				fmt.Fprintf(w, "\n%s: blocking tabs not passing\n", b.Version)
+				tw := output.TableWriter(w)
+				output.PrintRow(tw, "  TAB", "STATUS", "LAST RUN", "MESSAGE")
+				for _, t := range b.NotPassing {
+					output.PrintRow(tw, "  "+t.TabName, output.ColorStatus(t.OverallStatus), t.LastRunTimestamp,
+						output.TruncateString(firstLine(t.DetailedStatusMessage), 60))
+				}
+				if err := tw.Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// completeReleaseVersion completes the versions that have release dashboards
+func completeReleaseVersion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var versions []string
+	for _, d := range dashboardNames() {
+		if v, _, ok := report.ParseReleaseDashboard(d); ok && !slices.Contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+	return filterPrefix(versions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// tabStatusCounts formats the number of tabs per status, worst first
+func tabStatusCounts(tabs []client.TabSummary) string {
+	counts := make(map[string]int)
+	for _, t := range tabs {
+		counts[t.OverallStatus]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for s := range counts {
+		statuses = append(statuses, s)
+	}
+	slices.SortFunc(statuses, func(a, b string) int { return cmp.Or(client.CompareStatus(a, b), cmp.Compare(a, b)) })
+
+	parts := make([]string, len(statuses))
+	for i, s := range statuses {
+		parts[i] = fmt.Sprintf("%s:%d", s, counts[s])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releaseStatusCmd)
+
+	releaseStatusCmd.Flags().IntVar(&releaseMinors, "minors", report.DefaultMinors, "Number of recent minor releases shown with master")
+	releaseStatusCmd.Flags().IntVar(&releaseConcurrency, "concurrency", report.DefaultConcurrency, "Maximum number of dashboards fetched at once")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportGroup       string
+	reportDashboards  []string
+	reportTemplate    string
+	reportFormat      string
+	reportOut         string
+	reportTop         int
+	reportWindow      int
+	reportStaleAfter  time.Duration
+	reportConcurrency int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a Markdown or HTML status report",
+	Long: `Gather dashboard and tab summaries, the top failing and flaky tests and
+stale tabs for a group or a set of dashboards, and render them as a report
+ready to paste into an issue or send around.
+
+Reports are rendered with a built-in Markdown or self-contained HTML
+template, or with a user template passed via --template. Templates use Go's
+text/template syntax (html/template for HTML) and receive the same data
+that -o json prints, plus these functions:
+
+  badge <status>       status badge (emoji in Markdown, colored label in HTML)
+  truncate <s> <n>     shorten s to n characters
+  percent <f>          format a ratio such as 0.953 as "95.3%"
+  cell <s>             escape s for a Markdown table cell
+
+The format defaults to the extension of --template or --out, or Markdown.`,
+	Example: `  # Markdown report for the release group
+  testgrid report --group=sig-release
+
+  # Self-contained HTML file
+  testgrid report --group=sig-release --format=html --out=report.html
+
+  # Render a custom template
+  testgrid report --group=sig-release --template=release.md.tmpl
+
+  # Raw report data for other tools
+  testgrid report --group=sig-release -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportGroup == "" && len(reportDashboards) == 0 {
+			return fmt.Errorf("--group or --dashboard is required")
+		}
+
+		var format report.Format
+		switch {
+		case reportFormat != "":
+			f, err := report.ParseFormat(reportFormat)
+			if err != nil {
+				return err
+			}
+			format = f
+		case reportTemplate != "":
+			format = report.FormatForPath(reportTemplate)
+		case reportOut != "":
+			format = report.FormatForPath(reportOut)
+		default:
+			format = report.FormatMarkdown
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		group := reportGroup
+		if group != "" {
+			g, err := resolver.ResolveGroup(ctx, group)
+			if err != nil {
+				return err
+			}
+			group = g
+		}
+		dashboards, err := resolveDashboards(ctx, reportDashboards)
+		if err != nil {
+			return err
+		}
+
+		data, err := report.Gather(ctx, apiClient, report.Options{
+			Group:                group,
+			Dashboards:           dashboards,
+			Window:               reportWindow,
+			Top:                  reportTop,
+			StaleAfter:           reportStaleAfter,
+			IncludeInfraFailures: includeInfraFailures,
+			Concurrency:          reportConcurrency,
+		})
+		if err != nil {
+			return err
+		}
+		for _, e := range data.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		if reportOut == "" {
+			return formatter.Print(data, func(w io.Writer) error {
+				return report.Render(w, data, format, reportTemplate)
+			})
+		}
+
+		f, err := os.Create(reportOut)
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		if err := newFormatter(f).Print(data, func(w io.Writer) error {
+			return report.Render(w, data, format, reportTemplate)
+		}); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", reportOut)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVar(&reportGroup, "group", "", "Dashboard group to report on")
+	reportCmd.Flags().StringArrayVar(&reportDashboards, "dashboard", nil, "Dashboard to report on (repeatable)")
+	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "Path to a custom template")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "", "Report format: markdown, html")
+	reportCmd.Flags().StringVar(&reportOut, "out", "", "Write the report to this file instead of stdout")
+	reportCmd.Flags().IntVar(&reportTop, "top", report.DefaultTop, "Number of failing and flaky tests to list")
+	reportCmd.Flags().IntVar(&reportWindow, "window", report.DefaultWindow, "Number of recent results considered per test")
+	reportCmd.Flags().DurationVar(&reportStaleAfter, "stale-after", report.DefaultStaleAfter, "List tabs that have not run for this long as stale")
+	reportCmd.Flags().IntVar(&reportConcurrency, "concurrency", report.DefaultConcurrency, "Maximum number of requests at once")
+	addInfraFlag(reportCmd)
+
+	registerNameFlagCompletion(reportCmd)
+}
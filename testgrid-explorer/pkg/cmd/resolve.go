@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+)
+
+// resolveTarget maps the dashboard and tab of t to their canonical names
+//
+// Test names are matched exactly by the API and are left as given.
+func resolveTarget(ctx context.Context, t watch.Target) (watch.Target, error) {
+	var err error
+	if t.Tab == "" {
+		t.Dashboard, err = resolver.ResolveDashboard(ctx, t.Dashboard)
+	} else {
+		t.Dashboard, t.Tab, err = resolver.ResolveTab(ctx, t.Dashboard, t.Tab)
+	}
+	return t, err
+}
+
+// resolveDashboards maps each name to its canonical dashboard name
+func resolveDashboards(ctx context.Context, names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		d, err := resolver.ResolveDashboard(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// resolveGroups maps each name to its canonical group name
+func resolveGroups(ctx context.Context, names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		g, err := resolver.ResolveGroup(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, nil
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/sozercan/testgrid-explorer/pkg/client"
@@ -12,13 +15,15 @@ var (
 	baseURL      string
 	outputFormat string
 	apiClient    *client.Client
+	resolver     *client.Resolver
 	formatter    *output.Formatter
 )
 
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
-	Use:   "testgrid",
-	Short: "TestGrid API Explorer",
+	Use:           "testgrid",
+	SilenceErrors: true,
+	Short:         "TestGrid API Explorer",
 	Long: `A CLI tool to explore and validate the TestGrid API.
 
 TestGrid provides Kubernetes CI/CD test results. This tool allows you to
@@ -44,28 +49,70 @@ Examples:
   testgrid dashboards list -o json`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		// Initialize client and formatter
-		opts := []client.Option{}
-		if baseURL != "" {
-			opts = append(opts, client.WithBaseURL(baseURL))
-		}
-		apiClient = client.New(opts...)
-
-		format := output.FormatTable
-		if outputFormat == "json" {
-			format = output.FormatJSON
-		}
-		formatter = output.New(os.Stdout, format)
+		apiClient = newClient()
+		resolver = client.NewResolver(apiClient)
+		formatter = newFormatter(os.Stdout)
 	},
 }
 
+// newFormatter creates a formatter writing to w in the format chosen by -o
+func newFormatter(w io.Writer) *output.Formatter {
+	format := output.FormatTable
+	if outputFormat == "json" {
+		format = output.FormatJSON
+	}
+	return output.New(w, format)
+}
+
+// newClient creates an API client honoring the global flags
+func newClient(opts ...client.Option) *client.Client {
+	if baseURL != "" {
+		opts = append(opts, client.WithBaseURL(baseURL))
+	}
+	return client.New(opts...)
+}
+
+// exitError makes Execute exit with a specific code
+//
+// A nil err means the command has already reported its outcome and only
+// the exit code needs to be set.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+
+	code := 1
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.code
+		err = exitErr.err
+	}
+	if err != nil {
+		cmd.PrintErrln(cmd.ErrPrefix(), err.Error())
 	}
+	os.Exit(code)
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "Override the TestGrid API base URL")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table, json")
+	_ = rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
 }
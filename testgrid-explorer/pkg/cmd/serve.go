@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/cache"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+	"github.com/sozercan/testgrid-explorer/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr        string
+	serveCacheTTL    time.Duration
+	serveCacheDir    string
+	serveCacheSize   int
+	serveCORSOrigins []string
+	serveStreamPoll  time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a caching proxy for the TestGrid API",
+	Long: `Run an HTTP server that proxies /api/v1/* to the TestGrid API.
+
+Responses are cached in memory and on disk, identical concurrent requests
+share a single upstream fetch, and responses are gzip-compressed when the
+client accepts it. If the upstream API fails, the last cached response is
+served instead. /api/proxy/* is accepted as an alias for /api/v1/*.
+
+The server also exposes /api/health and Prometheus metrics on /metrics.
+
+No CORS headers are sent by default, so browsers only let pages served from
+the proxy's own origin read its responses. --cors-origin allows other
+origins; --cors-origin='*' allows any website.
+
+Live tab summary changes are pushed as Server-Sent Events on /api/stream.
+Clients select what they receive with repeated dashboard, tab or target
+(dashboard or dashboard/tab) query parameters. A single poller fetches
+each dashboard with connected clients, and reconnecting clients resume
+from their Last-Event-ID.`,
+	Example: `  # Serve on port 8080 with a 2 minute cache
+  testgrid serve --addr=:8080 --cache-ttl=2m
+
+  # Allow the Pano dev server to call the proxy
+  testgrid serve --cors-origin=http://localhost:5173
+
+  # Disable the disk cache
+  testgrid serve --cache-dir=""
+
+  # Follow live changes for one dashboard
+  curl -N 'http://localhost:8080/api/stream?dashboard=sig-release-master-blocking'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry := metrics.NewRegistry()
+		hc := metrics.InstrumentHTTPClient(&http.Client{Timeout: client.DefaultTimeout}, registry)
+		upstream := newClient(client.WithHTTPClient(hc))
+
+		srv := server.New(upstream,
+			server.WithCache(cache.New(serveCacheSize, serveCacheDir)),
+			server.WithCacheTTL(serveCacheTTL),
+			server.WithCORSOrigins(serveCORSOrigins...),
+			server.WithRegistry(registry),
+		)
+		hub := server.NewHub(upstream,
+			server.WithStreamInterval(serveStreamPoll),
+			server.WithStreamRegistry(registry),
+		)
+		srv.Handle("/api/stream", hub)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go hub.Run(ctx)
+		return listenAndServe(ctx, serveAddr, srv.Handler())
+	},
+}
+
+// listenAndServe runs h on addr until ctx is cancelled, then shuts down gracefully
+func listenAndServe(ctx context.Context, addr string, h http.Handler) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().DurationVar(&serveCacheTTL, "cache-ttl", server.DefaultCacheTTL, "How long responses are served from cache before refetching")
+	serveCmd.Flags().StringVar(&serveCacheDir, "cache-dir", cache.Dir("http"), "Directory for the on-disk cache (empty to disable)")
+	serveCmd.Flags().IntVar(&serveCacheSize, "cache-size", server.DefaultCacheMaxEntries, "Maximum number of responses kept in memory")
+	serveCmd.Flags().DurationVar(&serveStreamPoll, "stream-interval", server.DefaultStreamInterval, "How often dashboards with stream clients are polled")
+	serveCmd.Flags().StringSliceVar(&serveCORSOrigins, "cors-origin", nil, "Origins allowed to make cross-origin requests (repeatable, '*' for any)")
+}
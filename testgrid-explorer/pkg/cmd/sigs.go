@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sozercan/testgrid-explorer/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sigsGroup       string
+	sigsDashboards  []string
+	sigsOut         string
+	sigsTop         int
+	sigsWindow      int
+	sigsConcurrency int
+)
+
+var sigsCmd = &cobra.Command{
+	Use:   "sigs",
+	Short: "Roll up test health by SIG",
+	Long:  "Commands for aggregating test results by the Kubernetes SIG owning each test.",
+}
+
+var sigsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report failing and flaky tests per SIG",
+	Long: `Fetch every tab of a group or a set of dashboards and roll their tests
+up by SIG, ranking SIGs by failing tests, then failing tabs and flaky tests,
+and listing the top offenders of each SIG.
+
+A test's SIG comes from its [sig-*] tag; untagged tests belong to the SIG
+in the dashboard name (e.g. node for sig-node-release-blocking), or to
+"none". --sig limits the report to some SIGs, and --tag and --exclude-tag
+to some tests.
+
+The report is Markdown, ready to paste into an issue or a meeting agenda;
+-o json prints the underlying data.`,
+	Example: `  # SIG health across the release dashboards
+  testgrid sigs report --group=sig-release
+
+  # One SIG over the last 50 results, without tests already marked flaky
+  testgrid sigs report --group=sig-release --sig=storage --window=50 --exclude-tag=Flaky
+
+  # Write the report to a file
+  testgrid sigs report --dashboard=sig-release-master-blocking --dashboard=sig-release-master-informing --out=sigs.md`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sigsGroup == "" && len(sigsDashboards) == 0 {
+			return fmt.Errorf("--group or --dashboard is required")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		group := sigsGroup
+		if group != "" {
+			g, err := resolver.ResolveGroup(ctx, group)
+			if err != nil {
+				return err
+			}
+			group = g
+		}
+		dashboards, err := resolveDashboards(ctx, sigsDashboards)
+		if err != nil {
+			return err
+		}
+
+		data, err := report.GatherSIGs(ctx, apiClient, report.SIGOptions{
+			Group:                group,
+			Dashboards:           dashboards,
+			Window:               sigsWindow,
+			Top:                  sigsTop,
+			Filter:               testFilter(),
+			IncludeInfraFailures: includeInfraFailures,
+			Concurrency:          sigsConcurrency,
+		})
+		if err != nil {
+			return err
+		}
+		for _, e := range data.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		render := func(w io.Writer) error { return report.RenderSIGs(w, data) }
+		if sigsOut == "" {
+			return formatter.Print(data, render)
+		}
+
+		f, err := os.Create(sigsOut)
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		if err := newFormatter(f).Print(data, render); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", sigsOut)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sigsCmd)
+	sigsCmd.AddCommand(sigsReportCmd)
+
+	sigsReportCmd.Flags().StringVar(&sigsGroup, "group", "", "Dashboard group to report on")
+	sigsReportCmd.Flags().StringArrayVar(&sigsDashboards, "dashboard", nil, "Dashboard to report on (repeatable)")
+	sigsReportCmd.Flags().StringVar(&sigsOut, "out", "", "Write the report to this file instead of stdout")
+	sigsReportCmd.Flags().IntVar(&sigsTop, "top", 5, "Number of offenders to list per SIG")
+	sigsReportCmd.Flags().IntVar(&sigsWindow, "window", report.DefaultWindow, "Number of recent results considered per test")
+	sigsReportCmd.Flags().IntVar(&sigsConcurrency, "concurrency", report.DefaultConcurrency, "Maximum number of tabs fetched at once")
+	addTestFilterFlags(sigsReportCmd)
+	addInfraFlag(sigsReportCmd)
+
+	registerNameFlagCompletion(sigsReportCmd)
+}
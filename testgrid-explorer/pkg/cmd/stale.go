@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	staleGroups      []string
+	staleDashboards  []string
+	staleMultiple    float64
+	staleAll         bool
+	staleConcurrency int
+)
+
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "Find tabs whose jobs have stopped running on schedule",
+	Long: `Infer the run cadence of every tab in the selected groups and dashboards
+from the start times of its recent builds, and list the tabs whose last run
+is overdue by more than --multiple periods.
+
+The period is the median time between consecutive builds, so occasional
+retries or missed runs do not skew it. Tabs with fewer than four builds
+with start times are skipped. TestGrid's own status is shown alongside, as
+it often still reads PASSING for jobs that stopped running.`,
+	Example: `  # Overdue tabs in the release dashboards
+  testgrid stale --group=sig-release
+
+  # Jobs that missed 5 runs in a row
+  testgrid stale --dashboard=sig-release-master-informing --multiple=5
+
+  # The inferred cadence of every tab
+  testgrid stale --dashboard=sig-release-master-blocking --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(staleGroups) == 0 && len(staleDashboards) == 0 {
+			return fmt.Errorf("at least one --group or --dashboard is required")
+		}
+		if staleMultiple <= 0 {
+			return fmt.Errorf("--multiple must be positive")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		groups, err := resolveGroups(ctx, staleGroups)
+		if err != nil {
+			return err
+		}
+		dashboards, err := resolveDashboards(ctx, staleDashboards)
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			resp, err := apiClient.GetGroupDashboards(ctx, g)
+			if err != nil {
+				return fmt.Errorf("failed to list group %s: %w", g, err)
+			}
+			for _, d := range resp.Dashboards {
+				if !slices.Contains(dashboards, d.Name) {
+					dashboards = append(dashboards, d.Name)
+				}
+			}
+		}
+
+		res := analysis.FindStale(ctx, apiClient, dashboards, analysis.StaleOptions{
+			Multiple:    staleMultiple,
+			Concurrency: staleConcurrency,
+			All:         staleAll,
+			Now:         time.Now(),
+		})
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, e := range res.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		return formatter.Print(res, func(w io.Writer) error {
+			if len(res.Tabs) == 0 {
+				fmt.Fprintf(w, "No overdue tabs among %d checked (%d with too few builds to infer a cadence)\n", res.Checked, res.Uninferred)
+				return nil
+			}
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "DASHBOARD", "TAB", "PERIOD", "LAST RUN", "SINCE", "OVERDUE", "STATUS")
+			for _, t := range res.Tabs {
+				overdue := fmt.Sprintf("%.1fx", t.Overdue)
+				if t.Overdue >= staleMultiple {
+					overdue = output.ColorStatus("STALE") + " " + overdue
+				}
+				output.PrintRow(tw, t.Dashboard, t.Tab, t.Period, t.LastRun.Format(time.RFC3339), t.Since, overdue, output.ColorStatus(t.Status))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\n%d checked, %d with too few builds to infer a cadence\n", res.Checked, res.Uninferred)
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(staleCmd)
+
+	staleCmd.Flags().StringArrayVar(&staleGroups, "group", nil, "Dashboard group to check (repeatable)")
+	staleCmd.Flags().StringArrayVar(&staleDashboards, "dashboard", nil, "Dashboard to check (repeatable)")
+	staleCmd.Flags().Float64Var(&staleMultiple, "multiple", analysis.DefaultOverdueMultiple, "Report tabs whose last run is this many periods ago")
+	staleCmd.Flags().BoolVar(&staleAll, "all", false, "Show every tab with an inferred cadence, not only overdue ones")
+	staleCmd.Flags().IntVar(&staleConcurrency, "concurrency", analysis.DefaultConcurrency, "Maximum number of requests at once")
+
+	registerNameFlagCompletion(staleCmd)
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/sozercan/testgrid-explorer/pkg/subscriptions"
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subscriptionsFile        string
+	subscriptionsConcurrency int
+)
+
+var subscriptionsCmd = &cobra.Command{
+	Use:     "subscriptions",
+	Aliases: []string{"subs", "sub"},
+	Short:   "Manage subscriptions",
+	Long: `Commands for managing subscribed dashboards, tabs and tests.
+
+Subscriptions are stored locally using the same JSON format as Pano's
+subscription export, so files can be moved freely between the two.`,
+}
+
+var subscriptionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subscriptions",
+	Long:  "List all locally stored subscriptions.",
+	Example: `  # List subscriptions
+  testgrid subscriptions list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+
+		return formatter.Print(st.Subscriptions, func(w io.Writer) error {
+			if len(st.Subscriptions) == 0 {
+				fmt.Fprintln(w, "No subscriptions")
+				return nil
+			}
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "TYPE", "DASHBOARD", "TAB", "TEST", "CREATED")
+			for _, s := range st.Subscriptions {
+				output.PrintRow(tw, string(s.Type), s.DashboardName, s.TabName, output.TruncateString(s.TestName, 60), s.CreatedAt)
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+var subscriptionsAddCmd = &cobra.Command{
+	Use:               "add <dashboard> [tab] [test]",
+	Short:             "Subscribe to a dashboard, tab or test",
+	Long:              "Subscribe to a dashboard, a tab within it, or a single test within a tab.",
+	Args:              cobra.RangeArgs(1, 3),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # Subscribe to a dashboard
+  testgrid subscriptions add sig-release-master-blocking
+
+  # Subscribe to a tab
+  testgrid subscriptions add sig-release-master-blocking kind-master`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+
+		target, err := resolveTarget(context.Background(), targetFromArgs(args))
+		if err != nil {
+			return err
+		}
+
+		sub, added := st.Add(target, time.Now())
+		if !added {
+			fmt.Fprintf(os.Stdout, "Already subscribed to %s\n", sub.ID)
+			return nil
+		}
+		if err := st.Save(subscriptionsFile); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Subscribed to %s\n", sub.ID)
+		return nil
+	},
+}
+
+var subscriptionsRemoveCmd = &cobra.Command{
+	Use:     "remove <id | dashboard [tab] [test]>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a subscription",
+	Long:    "Remove a subscription by its ID or by the dashboard, tab and test it targets.",
+	Args:    cobra.RangeArgs(1, 3),
+	Example: `  # Remove by target
+  testgrid subscriptions remove sig-release-master-blocking kind-master
+
+  # Remove by ID
+  testgrid subscriptions remove tab:sig-release-master-blocking:kind-master`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+
+		id := subscriptions.GenerateID(targetFromArgs(args))
+		removed := st.Remove(id)
+		if !removed && len(args) == 1 {
+			id = args[0]
+			removed = st.Remove(id)
+		}
+		if !removed {
+			return fmt.Errorf("no subscription matches %s", id)
+		}
+		if err := st.Save(subscriptionsFile); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Removed %s\n", id)
+		return nil
+	},
+}
+
+var subscriptionsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import subscriptions exported from Pano",
+	Long: `Import subscriptions from a Pano export file, merging them with existing ones.
+
+Use - to read from stdin. Subscriptions that already exist are skipped.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  # Import a Pano export
+  testgrid subscriptions import pano-subscriptions.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		imported, err := subscriptions.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+		added := st.Merge(imported)
+		if err := st.Save(subscriptionsFile); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Imported %d new subscriptions (%d total)\n", added, len(st.Subscriptions))
+		return nil
+	},
+}
+
+var subscriptionsExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export subscriptions in Pano's format",
+	Long:  "Export subscriptions as JSON that can be imported into Pano. Writes to stdout unless a file is given.",
+	Args:  cobra.MaximumNArgs(1),
+	Example: `  # Export to a file
+  testgrid subscriptions export pano-subscriptions.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+
+		data, err := st.Export(time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to export subscriptions: %w", err)
+		}
+		data = append(data, '\n')
+
+		if len(args) == 0 {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		return os.WriteFile(args[0], data, 0o644)
+	},
+}
+
+var subscriptionsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current status of every subscription",
+	Long:  "Fetch the current status of every subscribed dashboard, tab and test in parallel.",
+	Example: `  # Show subscription status
+  testgrid subscriptions status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		st, err := subscriptions.Load(subscriptionsFile)
+		if err != nil {
+			return err
+		}
+
+		statuses := subscriptions.FetchStatuses(ctx, apiClient, st.Subscriptions, subscriptionsConcurrency)
+
+		return formatter.Print(statuses, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "TYPE", "TARGET", "STATUS", "MESSAGE")
+			for _, s := range statuses {
+				msg := s.Message
+				if s.Error != "" {
+					msg = s.Error
+				}
+				output.PrintRow(tw, string(s.Subscription.Type),
+					output.TruncateString(s.Subscription.Target().String(), 80),
+					output.ColorStatus(s.Status),
+					output.TruncateString(msg, 50))
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+// targetFromArgs builds a target from <dashboard> [tab] [test] arguments
+func targetFromArgs(args []string) watch.Target {
+	switch len(args) {
+	case 3:
+		return watch.Target{Type: watch.TargetTest, Dashboard: args[0], Tab: args[1], Test: args[2]}
+	case 2:
+		return watch.Target{Type: watch.TargetTab, Dashboard: args[0], Tab: args[1]}
+	default:
+		return watch.Target{Type: watch.TargetDashboard, Dashboard: args[0]}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(subscriptionsCmd)
+	subscriptionsCmd.AddCommand(subscriptionsListCmd)
+	subscriptionsCmd.AddCommand(subscriptionsAddCmd)
+	subscriptionsCmd.AddCommand(subscriptionsRemoveCmd)
+	subscriptionsCmd.AddCommand(subscriptionsImportCmd)
+	subscriptionsCmd.AddCommand(subscriptionsExportCmd)
+	subscriptionsCmd.AddCommand(subscriptionsStatusCmd)
+
+	subscriptionsCmd.PersistentFlags().StringVar(&subscriptionsFile, "file", subscriptions.DefaultPath(), "Subscriptions file")
+	subscriptionsStatusCmd.Flags().IntVar(&subscriptionsConcurrency, "concurrency", subscriptions.DefaultConcurrency, "Maximum number of concurrent requests")
+}
@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
 	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/junit"
 	"github.com/sozercan/testgrid-explorer/pkg/output"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +21,18 @@ import (
 var (
 	filterStatus string
 	limitRows    int
+	sortBy       string
+	exportFormat string
+	exportBuild  string
+	exportBuilds int
+	groupBySIG   bool
+
+	simFailuresToAlert []int
+	simPassesToDisable int
+	simRecentColumns   int
+	simStaleHours      float64
+	simBrokenThreshold float64
+	simEvents          int
 )
 
 var tabsCmd = &cobra.Command{
@@ -24,15 +43,19 @@ var tabsCmd = &cobra.Command{
 }
 
 var tabsListCmd = &cobra.Command{
-	Use:   "list <dashboard>",
-	Short: "List tabs in a dashboard",
-	Long:  "List all tabs belonging to a specific dashboard.",
-	Args:  cobra.ExactArgs(1),
+	Use:               "list <dashboard>",
+	Short:             "List tabs in a dashboard",
+	Long:              "List all tabs belonging to a specific dashboard.",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
 	Example: `  # List tabs in a dashboard
   testgrid tabs list sig-release-master-blocking`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.ListDashboardTabs(ctx, dashboard)
 		if err != nil {
@@ -53,16 +76,34 @@ var tabsListCmd = &cobra.Command{
 var tabsSummariesCmd = &cobra.Command{
 	Use:   "summaries <dashboard>",
 	Short: "List tab summaries for a dashboard",
-	Long:  "Get health summaries for all tabs in a dashboard.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Get health summaries for all tabs in a dashboard.
+
+Pass rates, column counts and failing test counts are parsed from each
+tab's status message and included as "stats" in JSON output. --sort orders
+tabs worst first by one of:
+
+  name      tab name
+  status    FAILING, BROKEN, FLAKY, STALE, PENDING, ACCEPTABLE, PASSING
+  pass      lowest recent column pass rate
+  cells     lowest cell pass rate
+  failing   most failing tests
+  last-run  least recently run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDashboard,
 	Example: `  # List tab summaries
   testgrid tabs summaries sig-release-master-blocking
 
   # Filter by status
-  testgrid tabs summaries sig-release-master-blocking --status=FAILING`,
+  testgrid tabs summaries sig-release-master-blocking --status=FAILING
+
+  # Show the tabs with the lowest pass rate first
+  testgrid tabs summaries sig-release-master-informing --sort=pass`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.ListTabSummaries(ctx, dashboard)
 		if err != nil {
@@ -80,12 +121,36 @@ var tabsSummariesCmd = &cobra.Command{
 			}
 		}
 
-		return formatter.Print(resp, func(w io.Writer) error {
+		summaries := make([]tabSummaryWithStats, 0, len(filtered))
+		for _, s := range filtered {
+			row := tabSummaryWithStats{TabSummary: s}
+			if stats, ok := s.Stats(); ok {
+				row.Stats = &stats
+			}
+			summaries = append(summaries, row)
+		}
+		if err := sortTabSummaries(summaries, sortBy); err != nil {
+			return err
+		}
+
+		data := struct {
+			TabSummaries []tabSummaryWithStats `json:"tab_summaries"`
+		}{summaries}
+
+		return formatter.Print(data, func(w io.Writer) error {
 			tw := output.TableWriter(w)
-			output.PrintRow(tw, "TAB", "STATUS", "LAST RUN", "MESSAGE")
-			for _, s := range filtered {
+			output.PrintRow(tw, "TAB", "STATUS", "PASS", "COLUMNS", "FAILING", "LAST RUN", "MESSAGE")
+			for _, s := range summaries {
+				pass, columns, failing := "-", "-", "-"
+				if s.Stats != nil && s.Stats.TotalColumns > 0 {
+					pass = fmt.Sprintf("%.1f%%", s.Stats.PassPercentage)
+					columns = fmt.Sprintf("%d/%d", s.Stats.PassedColumns, s.Stats.TotalColumns)
+				}
+				if s.Stats != nil {
+					failing = fmt.Sprint(s.Stats.FailingTests)
+				}
 				msg := output.TruncateString(s.DetailedStatusMessage, 50)
-				output.PrintRow(tw, s.TabName, output.ColorStatus(s.OverallStatus), s.LastRunTimestamp, msg)
+				output.PrintRow(tw, s.TabName, output.ColorStatus(s.OverallStatus), pass, columns, failing, s.LastRunTimestamp, msg)
 			}
 			return tw.Flush()
 		})
@@ -93,30 +158,49 @@ var tabsSummariesCmd = &cobra.Command{
 }
 
 var tabsSummaryCmd = &cobra.Command{
-	Use:   "summary <dashboard> <tab>",
-	Short: "Get summary for a specific tab",
-	Long:  "Get detailed summary for a specific tab.",
-	Args:  cobra.ExactArgs(2),
+	Use:               "summary <dashboard> <tab>",
+	Short:             "Get summary for a specific tab",
+	Long:              "Get detailed summary for a specific tab.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
 	Example: `  # Get tab summary
   testgrid tabs summary sig-release-master-blocking kind-master`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
-		tab := args[1]
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetTabSummary(ctx, dashboard, tab)
 		if err != nil {
 			return fmt.Errorf("failed to get tab summary: %w", err)
 		}
 
-		return formatter.Print(resp, func(w io.Writer) error {
-			s := resp.TabSummary
+		s := tabSummaryWithStats{TabSummary: resp.TabSummary}
+		if stats, ok := s.TabSummary.Stats(); ok {
+			s.Stats = &stats
+		}
+		data := struct {
+			TabSummary tabSummaryWithStats `json:"tab_summary"`
+		}{s}
+
+		return formatter.Print(data, func(w io.Writer) error {
 			fmt.Fprintf(w, "Dashboard:    %s\n", s.DashboardName)
 			fmt.Fprintf(w, "Tab:          %s\n", s.TabName)
 			fmt.Fprintf(w, "Status:       %s\n", output.ColorStatus(s.OverallStatus))
 			fmt.Fprintf(w, "Last Run:     %s\n", s.LastRunTimestamp)
 			fmt.Fprintf(w, "Last Update:  %s\n", s.LastUpdateTimestamp)
 			fmt.Fprintf(w, "Latest Pass:  %s\n", s.LatestPassingBuild)
+			if st := s.Stats; st != nil && st.TotalColumns > 0 {
+				fmt.Fprintf(w, "Columns:      %d of %d passed (%.1f%%)\n", st.PassedColumns, st.TotalColumns, st.PassPercentage)
+			}
+			if st := s.Stats; st != nil && st.TotalCells > 0 {
+				fmt.Fprintf(w, "Cells:        %d of %d passed (%.1f%%)\n", st.PassedCells, st.TotalCells, st.CellPassPercentage)
+			}
+			if st := s.Stats; st != nil && st.FailingTests > 0 {
+				fmt.Fprintf(w, "Failing:      %d tests\n", st.FailingTests)
+			}
 			fmt.Fprintln(w)
 			fmt.Fprintf(w, "Details: %s\n", s.DetailedStatusMessage)
 			return nil
@@ -125,16 +209,19 @@ var tabsSummaryCmd = &cobra.Command{
 }
 
 var tabsHeadersCmd = &cobra.Command{
-	Use:   "headers <dashboard> <tab>",
-	Short: "Get headers (columns) for a tab",
-	Long:  "Get build/column information for a tab's test grid.",
-	Args:  cobra.ExactArgs(2),
+	Use:               "headers <dashboard> <tab>",
+	Short:             "Get headers (columns) for a tab",
+	Long:              "Get build/column information for a tab's test grid.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
 	Example: `  # Get tab headers
   testgrid tabs headers sig-release-master-blocking gce-cos-master-default`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
-		tab := args[1]
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
 		if err != nil {
@@ -153,13 +240,243 @@ var tabsHeadersCmd = &cobra.Command{
 	},
 }
 
+var tabsBuildsCmd = &cobra.Command{
+	Use:   "builds <dashboard> <tab>",
+	Short: "Show per-build statistics for a tab",
+	Long: `Show how each build (column) of a tab did: its build ID, start time and
+commit, the number of passed, failed, skipped and empty cells, the time
+since the previous build started and whether it was green.
+
+A build is green when its Overall result passed, or, in tabs without an
+Overall row, when tests ran and none failed. The summary shows the last
+green build, the median time between builds and the time since the last
+one started, to tell whether builds are running on schedule.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # Show builds
+  testgrid tabs builds sig-release-master-blocking kind-master
+
+  # Only the last 5 builds
+  testgrid tabs builds sig-release-master-blocking kind-master --limit=5`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		headers, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab headers: %w", err)
+		}
+		rows, err := apiClient.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab rows: %w", err)
+		}
+
+		builds := analysis.AnalyzeBuilds(headers.Headers, rows.Rows, time.Now())
+		if limitRows > 0 && len(builds.Builds) > limitRows {
+			builds.Builds = builds.Builds[:limitRows]
+		}
+
+		return formatter.Print(builds, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "BUILD", "STARTED", "COMMIT", "PASS", "FAIL", "SKIP", "EMPTY", "GAP", "GREEN")
+			for _, b := range builds.Builds {
+				green := output.StatusColor("FAILING") + "✗" + output.ResetColor()
+				if b.Green {
+					green = output.StatusColor("PASSING") + "✓" + output.ResetColor()
+				}
+				if b.Infra != "" {
+					green += " infra"
+				}
+				output.PrintRow(tw, b.Build, b.Started, b.Commit,
+					fmt.Sprint(b.Pass), fmt.Sprint(b.Fail), fmt.Sprint(b.Skip), fmt.Sprint(b.Empty), b.Gap, green)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w)
+			lastGreen := builds.LastGreen
+			if lastGreen == "" {
+				lastGreen = "none"
+			}
+			fmt.Fprintf(w, "Last green:   %s\n", lastGreen)
+			if builds.MedianGap != "" {
+				fmt.Fprintf(w, "Median gap:   %s\n", builds.MedianGap)
+			}
+			if builds.SinceLast != "" {
+				fmt.Fprintf(w, "Since last:   %s\n", builds.SinceLast)
+			}
+			return nil
+		})
+	},
+}
+
+var tabsSimulateCmd = &cobra.Command{
+	Use:   "simulate <dashboard> <tab>",
+	Short: "Recompute a tab's status under different alerting settings",
+	Long: `Replay a tab's history with TestGrid-like rules to explain its status and
+show when alerts would have fired under different settings:
+
+  STALE    nothing ran for --stale-hours
+  PENDING  no recent column has results
+  FAILING  a test failed --failures-to-alert times in a row, until it
+           passed --passes-to-disable times in a row
+  BROKEN   --broken-threshold of the latest column's tests failed
+  FLAKY    a test failed within the last --recent-columns columns
+  PASSING  otherwise
+
+The simulated status is shown next to the status TestGrid reports. Pass
+several values to --failures-to-alert to compare them side by side; the
+alert history is shown for the first.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # Why is this tab FLAKY?
+  testgrid tabs simulate sig-release-master-blocking kind-master
+
+  # How often would it have alerted after 1, 2 or 5 consecutive failures?
+  testgrid tabs simulate sig-release-master-blocking kind-master --failures-to-alert=1,2,5
+
+  # Treat 12 hours without results as stale
+  testgrid tabs simulate sig-release-master-blocking kind-master --stale-hours=12`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if len(simFailuresToAlert) == 0 {
+			return fmt.Errorf("--failures-to-alert needs at least one value")
+		}
+
+		headers, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab headers: %w", err)
+		}
+		rows, err := apiClient.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab rows: %w", err)
+		}
+		// The reported status is only shown for comparison
+		var reported string
+		if summary, err := apiClient.GetTabSummary(ctx, dashboard, tab); err == nil {
+			reported = summary.TabSummary.OverallStatus
+		}
+
+		now := time.Now()
+		sims := make([]*analysis.Simulation, 0, len(simFailuresToAlert))
+		for _, n := range simFailuresToAlert {
+			sims = append(sims, analysis.Simulate(headers.Headers, rows.Rows, analysis.StatusOptions{
+				FailuresToAlert: n,
+				PassesToDisable: simPassesToDisable,
+				RecentColumns:   simRecentColumns,
+				StaleHours:      simStaleHours,
+				BrokenThreshold: simBrokenThreshold,
+			}, now))
+		}
+
+		data := struct {
+			Dashboard      string                 `json:"dashboard"`
+			Tab            string                 `json:"tab"`
+			TestGridStatus string                 `json:"testgrid_status,omitempty"`
+			Simulations    []*analysis.Simulation `json:"simulations"`
+		}{dashboard, tab, reported, sims}
+
+		return formatter.Print(data, func(w io.Writer) error {
+			if reported != "" {
+				fmt.Fprintf(w, "TestGrid status:  %s\n\n", output.ColorStatus(reported))
+			}
+
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "FAILURES TO ALERT", "STATUS", "ALERTS FIRED", "FAILING BUILDS", "REASON")
+			for _, s := range sims {
+				fired, failing := 0, 0
+				for _, e := range s.Events {
+					if e.Kind == analysis.AlertFired {
+						fired++
+					}
+				}
+				for _, c := range s.Columns {
+					if c.Status == "FAILING" {
+						failing++
+					}
+				}
+				output.PrintRow(tw, fmt.Sprint(s.Options.FailuresToAlert), output.ColorStatus(s.Status),
+					fmt.Sprint(fired), fmt.Sprintf("%d/%d", failing, len(s.Columns)), s.Reason)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			s := sims[0]
+			fmt.Fprintf(w, "\nHistory with --failures-to-alert=%d (recent → old):\n  ", s.Options.FailuresToAlert)
+			for _, c := range s.Columns {
+				fmt.Fprint(w, output.StatusColor(c.Status)+historySymbol(c.Status)+output.ResetColor())
+			}
+			fmt.Fprint(w, "\n  ")
+			for i, h := range historySymbols {
+				if i > 0 {
+					fmt.Fprint(w, "  ")
+				}
+				fmt.Fprint(w, output.StatusColor(h.status)+h.symbol+output.ResetColor()+" "+strings.ToLower(h.status))
+			}
+			fmt.Fprintln(w)
+			if len(s.Alerting) > 0 {
+				fmt.Fprintln(w, "\nAlerting:")
+				for _, t := range s.Alerting {
+					fmt.Fprintf(w, "  %s\n", t)
+				}
+			}
+			if len(s.Events) == 0 {
+				fmt.Fprintln(w, "\nNo alerts would have fired")
+				return nil
+			}
+
+			fmt.Fprintln(w)
+			tw = output.TableWriter(w)
+			output.PrintRow(tw, "EVENT", "BUILD", "STARTED", "TEST")
+			events := s.Events
+			if simEvents > 0 && len(events) > simEvents {
+				events = events[:simEvents]
+			}
+			for _, e := range events {
+				test := output.TruncateString(e.Test, 80)
+				if e.Kind == analysis.AlertFired {
+					test += fmt.Sprintf(" (after %d failures)", e.Failures)
+				}
+				output.PrintRow(tw, e.Kind, e.Build, e.Started, test)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if len(events) < len(s.Events) {
+				fmt.Fprintf(w, "\nShowing %d of %d events\n", len(events), len(s.Events))
+			}
+			return nil
+		})
+	},
+}
+
 var tabsRowsCmd = &cobra.Command{
 	Use:   "rows <dashboard> <tab>",
 	Short: "Get rows (test results) for a tab",
 	Long: `Get test results matrix for a tab.
 
+Kubernetes e2e test names encode their SIG and tags, e.g.
+"[sig-node] Pods should run [NodeConformance] [Conformance]". --sig, --tag
+and --exclude-tag filter rows on them; a tag key such as Feature matches
+any [Feature:X] tag. --group-by-sig aggregates the rows per SIG instead,
+counting untagged tests under the SIG in the dashboard name like sigs report.
+
+Build columns in which most tests failed, or in which the build failed
+before most tests ran, are marked as infrastructure failures. They are
+left out of --group-by-sig pass rates unless --include-infra-failures is set.
+
 Note: This can return large amounts of data. Use --limit to restrict output.`,
-	Args: cobra.ExactArgs(2),
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
 	Example: `  # Get all rows
   testgrid tabs rows sig-release-master-blocking gce-cos-master-default
 
@@ -167,23 +484,37 @@ Note: This can return large amounts of data. Use --limit to restrict output.`,
   testgrid tabs rows sig-release-master-blocking gce-cos-master-default --limit=10
 
   # Filter to failing tests only
-  testgrid tabs rows sig-release-master-blocking gce-cos-master-default --status=FAIL`,
+  testgrid tabs rows sig-release-master-blocking gce-cos-master-default --status=FAIL
+
+  # Conformance tests of SIG Storage, excluding flaky ones
+  testgrid tabs rows sig-release-master-blocking gce-cos-master-default --sig=storage --tag=Conformance --exclude-tag=Flaky
+
+  # Failing and flaky tests per SIG
+  testgrid tabs rows sig-release-master-blocking gce-cos-master-default --group-by-sig`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
-		dashboard := args[0]
-		tab := args[1]
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
 
 		resp, err := apiClient.GetTabRows(ctx, dashboard, tab)
 		if err != nil {
 			return fmt.Errorf("failed to get tab rows: %w", err)
 		}
 
+		infra := analysis.DetectInfraFailures(nil, resp.Rows, analysis.DefaultInfraOptions)
+		filtered := filterRowsByName(resp.Rows)
+		if groupBySIG {
+			return printSIGStats(analysis.GroupBySIG([]analysis.TabRows{{Dashboard: dashboard, Tab: tab, Rows: filterRowsByName(excludeInfra(resp.Rows))}}, 0))
+		}
+
 		// Filter by status if specified
-		filtered := resp.Rows
 		if filterStatus != "" {
 			targetResult := statusToResult(filterStatus)
+			byName := filtered
 			filtered = []client.Row{}
-			for _, r := range resp.Rows {
+			for _, r := range byName {
 				for _, c := range r.Cells {
 					if c.Result == targetResult {
 						filtered = append(filtered, r)
@@ -198,21 +529,158 @@ Note: This can return large amounts of data. Use --limit to restrict output.`,
 			filtered = filtered[:limitRows]
 		}
 
-		return formatter.Print(resp, func(w io.Writer) error {
+		data := struct {
+			Rows         []client.Row           `json:"rows"`
+			InfraColumns []analysis.InfraColumn `json:"infra_columns,omitempty"`
+		}{filtered, infra}
+
+		return formatter.Print(data, func(w io.Writer) error {
+			infraCols := make(map[int]bool, len(infra))
+			for _, ic := range infra {
+				infraCols[ic.Column] = true
+			}
+
 			tw := output.TableWriter(w)
 			output.PrintRow(tw, "TEST NAME", "RESULTS (recent → old)")
 			for _, r := range filtered {
-				results := formatCellResults(r.Cells, 20)
+				results := formatRowResults(r.Cells, 20, infraCols)
 				name := output.TruncateString(r.Name, 80)
 				output.PrintRow(tw, name, results)
 			}
 			tw.Flush()
 			fmt.Fprintf(w, "\nShowing %d of %d rows\n", len(filtered), len(resp.Rows))
+			if len(infra) > 0 {
+				fmt.Fprintln(w, "\nInfrastructure failures (shown as !):")
+				for _, ic := range infra {
+					fmt.Fprintf(w, "  column %d: %s\n", ic.Column+1, ic.Reason)
+				}
+			}
 			return nil
 		})
 	},
 }
 
+var tabsExportCmd = &cobra.Command{
+	Use:   "export <dashboard> <tab>",
+	Short: "Export a tab's results as JUnit XML",
+	Long: `Convert the results of one or more builds of a tab into a JUnit XML
+document that CI systems can ingest.
+
+Each build becomes a testsuite and each test that ran in it a testcase.
+Failure and skipped elements carry the cell message. --build=latest picks
+the most recent build that has results; --builds=N exports the N most
+recent builds with results, one suite per build.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # Export the latest build
+  testgrid tabs export sig-release-master-blocking kind-master --format=junit > junit.xml
+
+  # Export a specific build
+  testgrid tabs export sig-release-master-blocking kind-master --build=2016390463384694784
+
+  # Export the last 5 builds, one suite per build
+  testgrid tabs export sig-release-master-blocking kind-master --builds=5`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		if exportFormat != "junit" {
+			return fmt.Errorf("unsupported export format %q (supported: junit)", exportFormat)
+		}
+		if cmd.Flags().Changed("build") && exportBuilds > 0 {
+			return fmt.Errorf("--build and --builds are mutually exclusive")
+		}
+
+		headers, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab headers: %w", err)
+		}
+		rows, err := apiClient.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab rows: %w", err)
+		}
+		rows.Rows = filterRowsByName(rows.Rows)
+
+		var cols []int
+		if exportBuilds > 0 {
+			cols = junit.LatestColumns(headers.Headers, rows.Rows, exportBuilds)
+			if len(cols) == 0 {
+				return fmt.Errorf("no build of %s/%s has results", dashboard, tab)
+			}
+		} else {
+			col, err := junit.SelectColumn(headers.Headers, rows.Rows, exportBuild)
+			if err != nil {
+				return err
+			}
+			cols = []int{col}
+		}
+
+		return junit.Write(os.Stdout, junit.Convert(dashboard, tab, headers.Headers, rows.Rows, cols))
+	},
+}
+
+// tabSummaryWithStats is a tab summary with the statistics parsed from its message
+type tabSummaryWithStats struct {
+	client.TabSummary
+	Stats *client.TabStats `json:"stats,omitempty"`
+}
+
+// sortTabSummaries sorts tabs worst first by the given key, keeping API
+// order for ties and an empty key
+func sortTabSummaries(tabs []tabSummaryWithStats, key string) error {
+	// Tabs without stats sort after those with them
+	stat := func(t tabSummaryWithStats, f func(client.TabStats) float64, missing float64) float64 {
+		if t.Stats == nil {
+			return missing
+		}
+		return f(*t.Stats)
+	}
+
+	var cmpFunc func(a, b tabSummaryWithStats) int
+	switch key {
+	case "":
+		return nil
+	case "name":
+		cmpFunc = func(a, b tabSummaryWithStats) int { return cmp.Compare(a.TabName, b.TabName) }
+	case "status":
+		cmpFunc = func(a, b tabSummaryWithStats) int { return client.CompareStatus(a.OverallStatus, b.OverallStatus) }
+	case "pass":
+		pass := func(s client.TabStats) float64 {
+			if s.TotalColumns == 0 {
+				return math.Inf(1)
+			}
+			return s.PassPercentage
+		}
+		cmpFunc = func(a, b tabSummaryWithStats) int {
+			return cmp.Compare(stat(a, pass, math.Inf(1)), stat(b, pass, math.Inf(1)))
+		}
+	case "cells":
+		cells := func(s client.TabStats) float64 {
+			if s.TotalCells == 0 {
+				return math.Inf(1)
+			}
+			return s.CellPassPercentage
+		}
+		cmpFunc = func(a, b tabSummaryWithStats) int {
+			return cmp.Compare(stat(a, cells, math.Inf(1)), stat(b, cells, math.Inf(1)))
+		}
+	case "failing":
+		failing := func(s client.TabStats) float64 { return float64(s.FailingTests) }
+		cmpFunc = func(a, b tabSummaryWithStats) int {
+			return cmp.Compare(stat(b, failing, -1), stat(a, failing, -1))
+		}
+	case "last-run":
+		cmpFunc = func(a, b tabSummaryWithStats) int { return cmp.Compare(a.LastRunTimestamp, b.LastRunTimestamp) }
+	default:
+		return fmt.Errorf("unknown sort key %q (supported: name, status, pass, cells, failing, last-run)", key)
+	}
+	slices.SortStableFunc(tabs, cmpFunc)
+	return nil
+}
+
 func statusToResult(status string) int {
 	switch strings.ToUpper(status) {
 	case "PASS", "PASSING":
@@ -226,6 +694,25 @@ func statusToResult(status string) int {
 	}
 }
 
+// historySymbols are the symbols of simulated column statuses, in legend order
+var historySymbols = []struct{ status, symbol string }{
+	{"PASSING", "✓"},
+	{"FLAKY", "~"},
+	{"FAILING", "✗"},
+	{"BROKEN", "!"},
+	{"PENDING", "·"},
+}
+
+// historySymbol returns the symbol of a simulated column status
+func historySymbol(status string) string {
+	for _, h := range historySymbols {
+		if h.status == status {
+			return h.symbol
+		}
+	}
+	return "?"
+}
+
 func formatCellResults(cells []client.Cell, maxCells int) string {
 	var sb strings.Builder
 	count := len(cells)
@@ -256,16 +743,56 @@ func formatCellResults(cells []client.Cell, maxCells int) string {
 	return sb.String()
 }
 
+// formatRowResults is formatCellResults with the cells of infra failure
+// columns shown as "!"
+func formatRowResults(cells []client.Cell, maxCells int, infra map[int]bool) string {
+	var sb strings.Builder
+	for i, c := range cells[:min(len(cells), maxCells)] {
+		if infra[i] {
+			sb.WriteString("\033[33m!\033[0m")
+			continue
+		}
+		sb.WriteString(formatCellResults([]client.Cell{c}, 1))
+	}
+	if len(cells) > maxCells {
+		sb.WriteString(fmt.Sprintf(" (+%d more)", len(cells)-maxCells))
+	}
+	return sb.String()
+}
+
 func init() {
 	rootCmd.AddCommand(tabsCmd)
 	tabsCmd.AddCommand(tabsListCmd)
 	tabsCmd.AddCommand(tabsSummariesCmd)
 	tabsCmd.AddCommand(tabsSummaryCmd)
 	tabsCmd.AddCommand(tabsHeadersCmd)
+	tabsCmd.AddCommand(tabsBuildsCmd)
 	tabsCmd.AddCommand(tabsRowsCmd)
+	tabsCmd.AddCommand(tabsSimulateCmd)
+	tabsCmd.AddCommand(tabsExportCmd)
 
 	// Add filter flags to relevant commands
 	tabsSummariesCmd.Flags().StringVar(&filterStatus, "status", "", "Filter by status (PASSING, FAILING, FLAKY, STALE)")
+	tabsSummariesCmd.Flags().StringVar(&sortBy, "sort", "", "Sort worst first by: name, status, pass, cells, failing, last-run")
+	_ = tabsSummariesCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(
+		[]string{"name", "status", "pass", "cells", "failing", "last-run"}, cobra.ShellCompDirectiveNoFileComp))
 	tabsRowsCmd.Flags().StringVar(&filterStatus, "status", "", "Filter by cell status (PASS, FAIL, SKIP)")
 	tabsRowsCmd.Flags().IntVar(&limitRows, "limit", 0, "Limit number of rows returned")
+	tabsBuildsCmd.Flags().IntVar(&limitRows, "limit", 0, "Limit number of builds shown")
+	tabsRowsCmd.Flags().BoolVar(&groupBySIG, "group-by-sig", false, "Aggregate the rows by SIG instead of listing them")
+	addInfraFlag(tabsRowsCmd)
+	addTestFilterFlags(tabsRowsCmd)
+
+	defaults := analysis.DefaultStatusOptions
+	tabsSimulateCmd.Flags().IntSliceVar(&simFailuresToAlert, "failures-to-alert", []int{defaults.FailuresToAlert}, "Consecutive failures before a test alerts (comma-separated to compare)")
+	tabsSimulateCmd.Flags().IntVar(&simPassesToDisable, "passes-to-disable", defaults.PassesToDisable, "Consecutive passes before an alert is resolved")
+	tabsSimulateCmd.Flags().IntVar(&simRecentColumns, "recent-columns", defaults.RecentColumns, "Recent columns in which a failure makes the tab FLAKY")
+	tabsSimulateCmd.Flags().Float64Var(&simStaleHours, "stale-hours", defaults.StaleHours, "Hours without results before the tab is STALE (0 to disable)")
+	tabsSimulateCmd.Flags().Float64Var(&simBrokenThreshold, "broken-threshold", defaults.BrokenThreshold, "Share of failed tests in the latest column that makes the tab BROKEN (0 to disable)")
+	tabsSimulateCmd.Flags().IntVar(&simEvents, "events", 20, "Number of recent alert events to show (0 for all)")
+
+	tabsExportCmd.Flags().StringVar(&exportFormat, "format", "junit", "Export format: junit")
+	tabsExportCmd.Flags().StringVar(&exportBuild, "build", junit.LatestBuild, "Build id to export, or latest")
+	tabsExportCmd.Flags().IntVar(&exportBuilds, "builds", 0, "Export the N most recent builds, one suite per build")
+	addTestFilterFlags(tabsExportCmd)
 }
@@ -0,0 +1,566 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/export"
+	"github.com/sozercan/testgrid-explorer/pkg/output"
+	"github.com/sozercan/testgrid-explorer/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	findGroups      []string
+	findDashboards  []string
+	findConcurrency int
+	findBuilds      int
+	findIndex       bool
+	findIndexFile   string
+	findMaxAge      time.Duration
+	findRefresh     bool
+
+	testJob       string
+	testLimit     int
+	testUIURL     string
+	testProwURL   string
+	testGCSBucket string
+
+	churnSince         string
+	churnDB            string
+	churnMinSimilarity float64
+
+	skippedBuilds      int
+	skippedConcurrency int
+	skippedTests       bool
+)
+
+var testsCmd = &cobra.Command{
+	Use:   "tests",
+	Short: "Find and inspect individual tests",
+	Long:  "Commands for finding tests across dashboards and inspecting their history.",
+}
+
+var testsFindCmd = &cobra.Command{
+	Use:   "find <regex>",
+	Short: "Find a test across dashboards and tabs",
+	Long: `Search the test names of every tab in every dashboard, or in the
+dashboards selected with --group and --dashboard, and list each occurrence
+with its latest result, pass rate over the last --builds results and the
+message of its last failure.
+
+The regular expression uses Go syntax; prefix it with (?i) to ignore case.
+Matches can be narrowed further with --sig, --tag and --exclude-tag.
+
+Searching crawls the rows of every selected tab, which for the whole
+instance takes a while. With --index the crawl is saved and reused by later
+searches over the same dashboards until it is older than --max-age.`,
+	Args: cobra.ExactArgs(1),
+	Example: `  # Where does a test run?
+  testgrid tests find 'CSI Volumes.*should mount'
+
+  # Search one group, ignoring case
+  testgrid tests find '(?i)csi volumes' --group=sig-storage
+
+  # Build the index once, then search it instantly
+  testgrid tests find 'Conformance' --index
+  testgrid tests find 'sig-network.*DNS' --index`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		groups, err := resolveGroups(ctx, findGroups)
+		if err != nil {
+			return err
+		}
+		dashboards, err := resolveDashboards(ctx, findDashboards)
+		if err != nil {
+			return err
+		}
+		scope := search.Scope{Groups: groups, Dashboards: dashboards}
+
+		idx, err := loadTestIndex(ctx, scope)
+		if err != nil {
+			return err
+		}
+		for _, e := range idx.Errors {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", e)
+		}
+
+		var matches []search.Entry
+		filter := testFilter()
+		for _, m := range idx.Find(re) {
+			if filter.Match(m.Test) {
+				matches = append(matches, m)
+			}
+		}
+		result := struct {
+			Pattern   string         `json:"pattern"`
+			IndexedAt time.Time      `json:"indexed_at"`
+			Matches   []search.Entry `json:"matches"`
+		}{args[0], idx.BuiltAt, matches}
+
+		return formatter.Print(result, func(w io.Writer) error {
+			if len(matches) == 0 {
+				fmt.Fprintf(w, "No tests matching %q in %d tabs of %d dashboards\n", args[0], idx.Tabs, idx.Dashboards)
+				return nil
+			}
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "TEST", "DASHBOARD", "TAB", "LATEST", "PASS RATE", "LAST FAILURE")
+			for _, m := range matches {
+				output.PrintRow(tw,
+					output.TruncateString(m.Test, 80),
+					m.Dashboard,
+					m.Tab,
+					formatCellResults([]client.Cell{{Result: m.Result}}, 1),
+					formatMatrixCell(&m.MatrixCell, true),
+					output.TruncateString(m.LastFailure, 60),
+				)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\n%d occurrences in %d tabs of %d dashboards (indexed %s ago)\n",
+				len(matches), idx.Tabs, idx.Dashboards, time.Since(idx.BuiltAt).Round(time.Second))
+			return nil
+		})
+	},
+}
+
+var testsGetCmd = &cobra.Command{
+	Use:   "get <dashboard> <tab> <test>",
+	Short: "Show the history of a single test",
+	Long: `Show every result of one test in a tab, joined with the build ID, start
+time and commit of its column, with summary statistics, the complete
+failure messages and links to the TestGrid UI and each build's Prow page.
+
+Runs in build columns where most tests failed or did not run are marked as
+infrastructure failures and left out of the statistics unless
+--include-infra-failures is set.
+
+The test name may be given in any case or as a unique prefix. Prow links
+are derived from each column's build ID and assume the tab's Prow job has
+the same name as the tab, as most Kubernetes tabs do; pass --job when it
+does not.`,
+	Args:              cobra.ExactArgs(3),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # Show a test's history
+  testgrid tests get sig-release-master-blocking kind-master '[sig-node] Pods should run'
+
+  # Link builds to the right Prow job
+  testgrid tests get sig-release-master-blocking gce-cos-master-default '[sig-storage] CSI' --job=ci-kubernetes-e2e-gci-gce
+
+  # Only the last 10 runs
+  testgrid tests get sig-release-master-blocking kind-master Overall --limit=10`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, tab, err := resolver.ResolveTab(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		headers, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab headers: %w", err)
+		}
+		rows, err := apiClient.GetTabRows(ctx, dashboard, tab)
+		if err != nil {
+			return fmt.Errorf("failed to get tab rows: %w", err)
+		}
+		row, err := analysis.FindRow(rows.Rows, args[2])
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", dashboard, tab, err)
+		}
+
+		var infra []analysis.InfraColumn
+		if !includeInfraFailures {
+			infra = analysis.DetectInfraFailures(headers.Headers, rows.Rows, analysis.DefaultInfraOptions)
+		}
+		h := analysis.BuildHistory(dashboard, tab, headers.Headers, *row, infra)
+		job := testJob
+		if job == "" {
+			job = tab
+		}
+		for i := range h.Runs {
+			h.Runs[i].URL = client.ProwURL(testProwURL, testGCSBucket, job, h.Runs[i].Build)
+		}
+		if testLimit > 0 && len(h.Runs) > testLimit {
+			h.Runs = h.Runs[:testLimit]
+		}
+
+		detail := struct {
+			*analysis.TestHistory
+			Job         string `json:"job"`
+			TestGridURL string `json:"testgrid_url"`
+		}{h, job, client.TabURL(testUIURL, dashboard, tab, h.Test)}
+
+		return formatter.Print(detail, func(w io.Writer) error {
+			s := h.Stats
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "Test:", h.Test)
+			output.PrintRow(tw, "Dashboard:", dashboard)
+			output.PrintRow(tw, "Tab:", tab)
+			output.PrintRow(tw, "Runs:", fmt.Sprintf("%d (%d passed, %d failed, %d skipped)", s.Runs, s.Passes, s.Failures, s.Skipped))
+			if s.PassRate >= 0 {
+				output.PrintRow(tw, "Pass Rate:", fmt.Sprintf("%.1f%%", s.PassRate*100))
+			}
+			output.PrintRow(tw, "Consecutive Failures:", fmt.Sprint(s.Consecutive))
+			output.PrintRow(tw, "Flips:", fmt.Sprint(s.Flips))
+			if s.LastPass != "" {
+				output.PrintRow(tw, "Last Pass:", s.LastPass)
+			}
+			if s.LastFailure != "" {
+				output.PrintRow(tw, "Last Failure:", s.LastFailure)
+			}
+			output.PrintRow(tw, "TestGrid:", detail.TestGridURL)
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			if len(h.Runs) == 0 {
+				fmt.Fprintln(w, "\nNo results")
+				return nil
+			}
+			fmt.Fprintln(w)
+			tw = output.TableWriter(w)
+			output.PrintRow(tw, "RESULT", "BUILD", "STARTED", "COMMIT", "MESSAGE")
+			for _, r := range h.Runs {
+				status := formatCellResults([]client.Cell{{Result: r.Result}}, 1) + " " + r.Status
+				if r.Infra {
+					status += " (infra)"
+				}
+				output.PrintRow(tw,
+					status,
+					r.Build,
+					r.Started,
+					r.Commit,
+					output.TruncateString(firstLine(r.Message), 60),
+				)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			// Failure messages are often long stack traces, so they are
+			// printed in full below the table
+			for _, r := range h.Runs {
+				if r.Result != client.CellResultFail || r.Infra {
+					continue
+				}
+				fmt.Fprintf(w, "\n%s Build %s\n", formatCellResults([]client.Cell{{Result: r.Result}}, 1), r.Build)
+				if r.URL != "" {
+					fmt.Fprintf(w, "  %s\n", r.URL)
+				}
+				if r.Message != "" {
+					fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(r.Message, "\n", "\n  "))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var testsChurnCmd = &cobra.Command{
+	Use:   "churn <dashboard> <tab>",
+	Short: "Detect tests that were added, removed or renamed",
+	Long: `Compare the tests that ran in a tab before --since with those that ran
+after it, and list the tests that vanished, the tests that appeared and
+the pairs that were likely renamed.
+
+A removed and an added test are reported as a rename when their names are
+similar once tags, SIGs and punctuation are ignored (--min-similarity) and
+the last results of the removed test equal the first results of the added
+one.
+
+By default the tab's current grid is used, which only reaches back as far
+as TestGrid keeps columns. Pass --db to use a database written by
+"testgrid export sqlite" instead, which keeps every build of every export.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDashboardTab,
+	Example: `  # What changed in the last week?
+  testgrid tests churn sig-release-master-blocking kind-master --since=7d
+
+  # Look further back using exported history
+  testgrid tests churn sig-release-master-blocking kind-master --since=30d --db=testgrid.db`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		age, err := parseAge(churnSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+
+		dashboard, tab := args[0], args[1]
+		var headers []client.Header
+		var rows []client.Row
+		if churnDB != "" {
+			store, err := export.Open(ctx, churnDB)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			if headers, rows, err = store.LoadTab(ctx, dashboard, tab); err != nil {
+				return err
+			}
+		} else {
+			if dashboard, tab, err = resolver.ResolveTab(ctx, dashboard, tab); err != nil {
+				return err
+			}
+			h, err := apiClient.GetTabHeaders(ctx, dashboard, tab)
+			if err != nil {
+				return fmt.Errorf("failed to get tab headers: %w", err)
+			}
+			r, err := apiClient.GetTabRows(ctx, dashboard, tab)
+			if err != nil {
+				return fmt.Errorf("failed to get tab rows: %w", err)
+			}
+			headers, rows = h.Headers, r.Rows
+		}
+
+		churn, err := analysis.DetectChurn(headers, rows, analysis.ChurnOptions{
+			Since:         time.Now().Add(-age),
+			MinSimilarity: churnMinSimilarity,
+		})
+		if err != nil {
+			if churnDB == "" {
+				return fmt.Errorf("%s/%s: %w; try a shorter --since or --db", dashboard, tab, err)
+			}
+			return fmt.Errorf("%s/%s: %w", dashboard, tab, err)
+		}
+
+		data := struct {
+			Dashboard string `json:"dashboard"`
+			Tab       string `json:"tab"`
+			*analysis.Churn
+		}{dashboard, tab, churn}
+
+		return formatter.Print(data, func(w io.Writer) error {
+			fmt.Fprintf(w, "%d tests in %d builds before %s, %d builds since\n",
+				churn.Tests, churn.Baseline, churn.Options.Since.Format(time.DateOnly), churn.Recent)
+			if len(churn.Removed)+len(churn.Added)+len(churn.Renamed) == 0 {
+				fmt.Fprintln(w, "\nNo tests were added, removed or renamed")
+				return nil
+			}
+
+			tw := output.TableWriter(w)
+			fmt.Fprintln(tw)
+			output.PrintRow(tw, "CHANGE", "TEST", "BUILDS")
+			for _, t := range churn.Removed {
+				output.PrintRow(tw, "removed", output.TruncateString(t.Name, 100), "last ran in "+t.LastRun)
+			}
+			for _, r := range churn.Renamed {
+				output.PrintRow(tw, fmt.Sprintf("renamed (%.0f%%)", r.Similarity*100), output.TruncateString(r.From.Name, 100), "last ran in "+r.From.LastRun)
+				output.PrintRow(tw, "", "→ "+output.TruncateString(r.To.Name, 98), "first ran in "+r.To.FirstRun)
+			}
+			for _, t := range churn.Added {
+				output.PrintRow(tw, "added", output.TruncateString(t.Name, 100), "first ran in "+t.FirstRun)
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+var testsSkippedCmd = &cobra.Command{
+	Use:   "skipped <dashboard> [tab...]",
+	Short: "Find tests that are skipped in every build",
+	Long: `List the tests whose results in the last --builds columns are all skipped
+or empty, with at least one skip. Such tests look covered on a dashboard
+but have not actually run.
+
+Tests are grouped by the first line of their latest skip message, with
+totals per tab and for the dashboard. Every tab of the dashboard is audited
+unless tabs are given. Narrow the audit to a SIG's tests with --sig.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDashboardTabs,
+	Example: `  # Audit a dashboard
+  testgrid tests skipped sig-release-master-blocking
+
+  # Audit two tabs over every column TestGrid keeps
+  testgrid tests skipped sig-release-master-blocking kind-master gce-cos-master-default --builds=0
+
+  # Dead coverage owned by SIG Storage, with every test listed
+  testgrid tests skipped sig-storage-kubernetes --sig=storage --tests`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		dashboard, err := resolver.ResolveDashboard(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		var tabs []analysis.TabRows
+		if len(args) == 1 {
+			var tabErrs []error
+			tabs, tabErrs, err = analysis.FetchDashboardRows(ctx, apiClient, dashboard, skippedConcurrency)
+			if err != nil {
+				return err
+			}
+			for _, e := range tabErrs {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+			}
+		} else {
+			for _, name := range args[1:] {
+				_, tab, err := resolver.ResolveTab(ctx, dashboard, name)
+				if err != nil {
+					return err
+				}
+				rows, err := apiClient.GetTabRows(ctx, dashboard, tab)
+				if err != nil {
+					return fmt.Errorf("failed to get rows of %s: %w", tab, err)
+				}
+				tabs = append(tabs, analysis.TabRows{Dashboard: dashboard, Tab: tab, Rows: rows.Rows})
+			}
+		}
+		for i := range tabs {
+			tabs[i].Rows = filterRowsByName(tabs[i].Rows)
+		}
+
+		audit := analysis.AuditSkipped(dashboard, tabs, skippedBuilds)
+		return formatter.Print(audit, func(w io.Writer) error {
+			tw := output.TableWriter(w)
+			output.PrintRow(tw, "TAB", "TESTS", "SKIPPED", "SHARE")
+			for _, t := range audit.Tabs {
+				output.PrintRow(tw, t.Tab, fmt.Sprint(t.Tests), fmt.Sprint(t.Skipped), skippedShare(t.Skipped, t.Tests))
+			}
+			output.PrintRow(tw, "TOTAL", fmt.Sprint(audit.Tests), fmt.Sprint(audit.Skipped), skippedShare(audit.Skipped, audit.Tests))
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if len(audit.Reasons) == 0 {
+				fmt.Fprintln(w, "\nNo tests are only skipped")
+				return nil
+			}
+
+			fmt.Fprintln(w)
+			tw = output.TableWriter(w)
+			output.PrintRow(tw, "TESTS", "REASON")
+			for _, r := range audit.Reasons {
+				output.PrintRow(tw, fmt.Sprint(len(r.Tests)), output.TruncateString(r.Reason, 100))
+				if !skippedTests {
+					continue
+				}
+				for _, t := range r.Tests {
+					output.PrintRow(tw, "", "  "+t.Tab+": "+output.TruncateString(t.Test, 100))
+				}
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+// skippedShare formats skipped as a percentage of tests
+func skippedShare(skipped, tests int) string {
+	if tests == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(skipped)/float64(tests)*100)
+}
+
+// parseAge parses a duration like "36h", also accepting whole days like "7d"
+func parseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%q is not positive", s)
+	}
+	return d, nil
+}
+
+// firstLine returns s up to its first line break
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// loadTestIndex returns the persisted index if --index is set and it is
+// fresh for scope, and otherwise crawls, saving the result with --index
+func loadTestIndex(ctx context.Context, scope search.Scope) (*search.Index, error) {
+	opts := search.BuildOptions{
+		Scope:                scope,
+		Concurrency:          findConcurrency,
+		Window:               findBuilds,
+		IncludeInfraFailures: includeInfraFailures,
+	}
+	if findIndex && !findRefresh {
+		idx, err := search.Load(findIndexFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ignoring index: %v\n", err)
+		}
+		if idx != nil && idx.Fresh(opts, findMaxAge, time.Now()) {
+			return idx, nil
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Crawling tabs, this may take a while...")
+	idx, err := search.Build(ctx, apiClient, opts)
+	if err != nil {
+		return nil, err
+	}
+	if findIndex || findRefresh {
+		if err := idx.Save(findIndexFile); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(testsCmd)
+	testsCmd.AddCommand(testsFindCmd)
+	testsCmd.AddCommand(testsGetCmd)
+	testsCmd.AddCommand(testsChurnCmd)
+	testsCmd.AddCommand(testsSkippedCmd)
+
+	testsFindCmd.Flags().StringArrayVar(&findGroups, "group", nil, "Only search dashboards in this group (repeatable)")
+	testsFindCmd.Flags().StringArrayVar(&findDashboards, "dashboard", nil, "Only search this dashboard (repeatable)")
+	testsFindCmd.Flags().IntVar(&findConcurrency, "concurrency", search.DefaultConcurrency, "Maximum number of requests at once")
+	testsFindCmd.Flags().IntVar(&findBuilds, "builds", search.DefaultWindow, "Number of recent results used for pass rates")
+	testsFindCmd.Flags().BoolVar(&findIndex, "index", false, "Reuse and update the persisted index")
+	testsFindCmd.Flags().StringVar(&findIndexFile, "index-file", search.DefaultPath(), "File the index is persisted to")
+	testsFindCmd.Flags().DurationVar(&findMaxAge, "max-age", 6*time.Hour, "Rebuild the index when it is older than this")
+	testsFindCmd.Flags().BoolVar(&findRefresh, "refresh", false, "Rebuild and save the index even if it is fresh")
+
+	addTestFilterFlags(testsFindCmd)
+	addInfraFlag(testsFindCmd)
+	registerNameFlagCompletion(testsFindCmd)
+
+	addInfraFlag(testsGetCmd)
+	testsGetCmd.Flags().StringVar(&testJob, "job", "", "Prow job of the tab for build links (default: the tab name)")
+	testsGetCmd.Flags().IntVar(&testLimit, "limit", 0, "Only show this many recent runs (0 for all)")
+	testsGetCmd.Flags().StringVar(&testUIURL, "testgrid-url", client.DefaultUIURL, "TestGrid UI base URL for links")
+	testsGetCmd.Flags().StringVar(&testProwURL, "prow-url", client.DefaultProwURL, "Prow base URL for build links")
+	testsGetCmd.Flags().StringVar(&testGCSBucket, "gcs-bucket", client.DefaultGCSBucket, "GCS bucket holding the job's artifacts")
+
+	testsChurnCmd.Flags().StringVar(&churnSince, "since", "7d", "Compare builds from this long ago, e.g. 7d or 36h, with those before")
+	testsChurnCmd.Flags().StringVar(&churnDB, "db", "", "Read the tab's history from this database instead of the API")
+	testsChurnCmd.Flags().Float64Var(&churnMinSimilarity, "min-similarity", analysis.DefaultChurnOptions.MinSimilarity, "Name similarity (0-1) above which a removed and an added test may be a rename")
+
+	addTestFilterFlags(testsSkippedCmd)
+	testsSkippedCmd.Flags().IntVar(&skippedBuilds, "builds", 10, "Number of recent columns a test must not have run in (0 for all)")
+	testsSkippedCmd.Flags().IntVar(&skippedConcurrency, "concurrency", analysis.DefaultConcurrency, "Maximum number of tabs fetched at once")
+	testsSkippedCmd.Flags().BoolVar(&skippedTests, "tests", false, "List the tests under each reason")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/subscriptions"
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval  time.Duration
+	watchStateFile string
+	watchOnce      bool
+	watchStdout    bool
+	watchWebhooks  []string
+	watchExecHooks []string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [target...]",
+	Short: "Watch dashboards, tabs or tests for status changes",
+	Long: `Poll dashboards, tabs or tests on an interval and report status transitions.
+
+Targets are given as dashboard, dashboard/tab or dashboard/tab/test. A
+dashboard target watches every tab in it. With no targets, every entry in
+the subscriptions file is watched.
+
+Events are only emitted when a status changes (to FAILING, to STALE, or
+back to PASSING from FAILING or STALE), and the last known statuses are
+persisted so restarts do not re-fire alerts.
+
+Events are written to stdout and can additionally be POSTed as JSON to
+webhooks or passed to commands. Exec hooks receive the event as JSON on
+stdin and as TESTGRID_* environment variables.`,
+	Example: `  # Watch all subscriptions
+  testgrid watch
+
+  # Watch a whole dashboard every 5 minutes
+  testgrid watch sig-release-master-blocking --interval=5m
+
+  # Watch a single tab and post changes to a webhook
+  testgrid watch sig-release-master-blocking/kind-master --webhook=https://example.com/hook
+
+  # Run one check from cron and page on changes
+  testgrid watch sig-release-master-blocking --once --exec='./page.sh'`,
+	ValidArgsFunction: completeTarget,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets := make([]watch.Target, 0, len(args))
+		for _, arg := range args {
+			t, err := watch.ParseTarget(arg)
+			if err != nil {
+				return err
+			}
+			if t, err = resolveTarget(context.Background(), t); err != nil {
+				return err
+			}
+			targets = append(targets, t)
+		}
+		if len(targets) == 0 {
+			st, err := subscriptions.Load(subscriptionsFile)
+			if err != nil {
+				return err
+			}
+			targets = st.Targets()
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("no targets given and no subscriptions found in %s", subscriptionsFile)
+		}
+
+		var notifiers []watch.Notifier
+		if watchStdout {
+			notifiers = append(notifiers, watch.NewStdoutNotifier(os.Stdout, outputFormat == "json"))
+		}
+		for _, url := range watchWebhooks {
+			notifiers = append(notifiers, watch.NewWebhookNotifier(url, nil))
+		}
+		for _, command := range watchExecHooks {
+			notifiers = append(notifiers, watch.NewExecNotifier(command))
+		}
+
+		state, err := watch.LoadState(watchStateFile)
+		if err != nil {
+			return err
+		}
+
+		w := watch.New(apiClient, targets,
+			watch.WithNotifiers(notifiers...),
+			watch.WithState(state, watchStateFile),
+			watch.WithErrorOutput(os.Stderr),
+		)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if watchOnce {
+			if _, err := w.Poll(ctx); err != nil {
+				return fmt.Errorf("failed to poll targets: %w", err)
+			}
+			return nil
+		}
+
+		if watchInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		return w.Run(ctx, watchInterval)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Minute, "Polling interval")
+	watchCmd.Flags().StringVar(&watchStateFile, "state-file", watch.DefaultStatePath(), "File used to persist last known statuses")
+	watchCmd.Flags().StringVar(&subscriptionsFile, "subscriptions", subscriptions.DefaultPath(), "Subscriptions file used when no targets are given")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Poll once and exit")
+	watchCmd.Flags().BoolVar(&watchStdout, "stdout", true, "Write events to stdout")
+	watchCmd.Flags().StringArrayVar(&watchWebhooks, "webhook", nil, "POST events as JSON to this URL (repeatable)")
+	watchCmd.Flags().StringArrayVar(&watchExecHooks, "exec", nil, "Run this shell command for each event (repeatable)")
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// DefaultConcurrency is the number of tabs fetched at once
+const DefaultConcurrency = 8
+
+// API is the subset of the TestGrid client used to crawl grids
+type API interface {
+	ListDashboardGroups(ctx context.Context) (*client.DashboardGroupsResponse, error)
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// CrawlOptions selects what is exported
+type CrawlOptions struct {
+	Groups      []string
+	Dashboards  []string
+	Concurrency int
+	// SummariesOnly skips headers and rows, which are by far the largest part
+	SummariesOnly bool
+}
+
+// Stats counts what a crawl wrote
+type Stats struct {
+	Groups     int `json:"groups"`
+	Dashboards int `json:"dashboards"`
+	Tabs       int `json:"tabs"`
+	Builds     int `json:"builds"`
+	Tests      int `json:"tests"`
+	Cells      int `json:"cells"`
+	Errors     int `json:"errors"`
+}
+
+// Crawl fetches the selected groups and dashboards and upserts them into s
+//
+// Errors for individual dashboards and tabs are counted and returned
+// together, but do not stop the rest from being exported.
+func Crawl(ctx context.Context, api API, s *Store, opts CrawlOptions) (Stats, error) {
+	var stats Stats
+	var errs []error
+	fail := func(err error) {
+		stats.Errors++
+		errs = append(errs, err)
+	}
+
+	groupLinks := make(map[string]string)
+	if len(opts.Groups) > 0 {
+		resp, err := api.ListDashboardGroups(ctx)
+		if err != nil {
+			return stats, fmt.Errorf("listing groups: %w", err)
+		}
+		for _, g := range resp.DashboardGroups {
+			groupLinks[g.Name] = g.Link
+		}
+	}
+
+	// Dashboards are deduplicated, keeping the group they were found in
+	var dashboards []client.Dashboard
+	groupOf := make(map[string]string)
+	add := func(d client.Dashboard, group string) error {
+		if _, ok := groupOf[d.Name]; ok {
+			return nil
+		}
+		groupOf[d.Name] = group
+		dashboards = append(dashboards, d)
+		return s.UpsertDashboard(ctx, d, group)
+	}
+
+	for _, g := range opts.Groups {
+		resp, err := api.GetGroupDashboards(ctx, g)
+		if err != nil {
+			fail(fmt.Errorf("group %s: %w", g, err))
+			continue
+		}
+		if err := s.UpsertGroup(ctx, client.DashboardGroup{Name: g, Link: groupLinks[g]}); err != nil {
+			return stats, err
+		}
+		stats.Groups++
+		for _, d := range resp.Dashboards {
+			if err := add(d, g); err != nil {
+				return stats, err
+			}
+		}
+	}
+	for _, d := range opts.Dashboards {
+		if err := add(client.Dashboard{Name: d}, ""); err != nil {
+			return stats, err
+		}
+	}
+	stats.Dashboards = len(dashboards)
+
+	var tabs []Tab
+	for _, d := range dashboards {
+		dashTabs, err := listTabs(ctx, api, d.Name)
+		if err != nil {
+			fail(fmt.Errorf("dashboard %s: %w", d.Name, err))
+			continue
+		}
+		tabs = append(tabs, dashTabs...)
+	}
+
+	var mu sync.Mutex
+	g := parallel.NewGroup(opts.Concurrency)
+	for _, t := range tabs {
+		g.Go(func() {
+			counts, err := exportTab(ctx, api, s, t, opts.SummariesOnly)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fail(err)
+				return
+			}
+			stats.Tabs++
+			stats.Builds += counts.Builds
+			stats.Tests += counts.Tests
+			stats.Cells += counts.Cells
+		})
+	}
+	g.Wait()
+
+	return stats, errors.Join(errs...)
+}
+
+// listTabs joins a dashboard's tab links with its tab summaries
+func listTabs(ctx context.Context, api API, dashboard string) ([]Tab, error) {
+	resp, err := api.ListDashboardTabs(ctx, dashboard)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := api.ListTabSummaries(ctx, dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*client.TabSummary, len(summaries.TabSummaries))
+	for i := range summaries.TabSummaries {
+		byName[summaries.TabSummaries[i].TabName] = &summaries.TabSummaries[i]
+	}
+
+	tabs := make([]Tab, 0, len(resp.DashboardTabs))
+	for _, t := range resp.DashboardTabs {
+		tabs = append(tabs, Tab{Dashboard: dashboard, Tab: t, Summary: byName[t.Name]})
+	}
+	return tabs, nil
+}
+
+func exportTab(ctx context.Context, api API, s *Store, t Tab, summariesOnly bool) (TabCounts, error) {
+	if !summariesOnly {
+		headers, err := api.GetTabHeaders(ctx, t.Dashboard, t.Tab.Name)
+		if err != nil {
+			return TabCounts{}, fmt.Errorf("tab %s/%s: %w", t.Dashboard, t.Tab.Name, err)
+		}
+		rows, err := api.GetTabRows(ctx, t.Dashboard, t.Tab.Name)
+		if err != nil {
+			return TabCounts{}, fmt.Errorf("tab %s/%s: %w", t.Dashboard, t.Tab.Name, err)
+		}
+		t.Headers = headers.Headers
+		t.Rows = rows.Rows
+	}
+	return s.WriteTab(ctx, t, time.Now())
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// fakeAPI serves one group with a healthy and a broken dashboard; builds
+// controls which columns the kind-master tab returns
+type fakeAPI struct {
+	builds []string
+}
+
+func (fakeAPI) ListDashboardGroups(ctx context.Context) (*client.DashboardGroupsResponse, error) {
+	return &client.DashboardGroupsResponse{DashboardGroups: []client.DashboardGroup{{Name: "sig-release", Link: "/sig-release"}}}, nil
+}
+
+func (fakeAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{
+		{Name: "blocking", Link: "/blocking"},
+		{Name: "broken"},
+	}}, nil
+}
+
+func (fakeAPI) ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error) {
+	if dashboard == "broken" {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "kind-master", Link: "/kind-master"}}}, nil
+}
+
+func (fakeAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{
+		{TabName: "kind-master", OverallStatus: "FLAKY", LastUpdateTimestamp: "2026-01-28T18:16:55Z"},
+	}}, nil
+}
+
+func (f fakeAPI) GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error) {
+	resp := &client.HeadersResponse{}
+	for _, b := range f.builds {
+		resp.Headers = append(resp.Headers, client.Header{Build: b, Extra: []string{"v1.36.0"}})
+	}
+	return resp, nil
+}
+
+func (f fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	pass := make([]client.Cell, len(f.builds))
+	flaky := make([]client.Cell, len(f.builds))
+	for i := range f.builds {
+		pass[i] = client.Cell{Result: client.CellResultPass}
+		flaky[i] = client.Cell{Result: client.CellResultFail, Message: "timed out"}
+	}
+	// The newest column of the flaky test has not run yet
+	flaky[0] = client.Cell{}
+	return &client.RowsResponse{Rows: []client.Row{{Name: "Overall", Cells: pass}, {Name: "flaky", Cells: flaky}}}, nil
+}
+
+func count(t *testing.T, s *Store, query string) int {
+	t.Helper()
+	var n int
+	if err := s.DB().QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestCrawlIncremental(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "testgrid.db")
+
+	s, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	opts := CrawlOptions{Groups: []string{"sig-release"}, Dashboards: []string{"blocking"}, Concurrency: 2}
+	stats, err := Crawl(ctx, fakeAPI{builds: []string{"2", "1"}}, s, opts)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected error for broken dashboard, got %v", err)
+	}
+	want := Stats{Groups: 1, Dashboards: 2, Tabs: 1, Builds: 2, Tests: 2, Cells: 3, Errors: 1}
+	if stats != want {
+		t.Errorf("expected stats %+v, got %+v", want, stats)
+	}
+	s.Close()
+
+	// A later run sees a new build; reopening extends the same database
+	s, err = Open(ctx, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+	if _, err := Crawl(ctx, fakeAPI{builds: []string{"3", "2", "1"}}, s, opts); err == nil {
+		t.Error("expected error for broken dashboard")
+	}
+
+	for query, want := range map[string]int{
+		"SELECT COUNT(*) FROM groups":                                      1,
+		"SELECT COUNT(*) FROM dashboards WHERE group_name = 'sig-release'": 2,
+		"SELECT COUNT(*) FROM dashboards WHERE link = '/blocking'":         1,
+		"SELECT COUNT(*) FROM tabs":                                        1,
+		"SELECT COUNT(*) FROM tab_summaries":                               1,
+		"SELECT COUNT(*) FROM builds":                                      3,
+		"SELECT COUNT(*) FROM tests":                                       2,
+		"SELECT COUNT(*) FROM cells":                                       5,
+		`SELECT COUNT(*) FROM builds WHERE extra = '["v1.36.0"]'`:          3,
+		`SELECT COUNT(*) FROM cells c JOIN results r ON r.code = c.result
+			JOIN tests t ON t.id = c.test_id WHERE r.name = 'FAIL' AND t.name = 'flaky' AND c.message = 'timed out'`: 2,
+	} {
+		if got := count(t, s, query); got != want {
+			t.Errorf("%s: expected %d, got %d", query, want, got)
+		}
+	}
+}
+
+func TestCrawlSummariesOnly(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, filepath.Join(t.TempDir(), "testgrid.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	stats, err := Crawl(ctx, fakeAPI{builds: []string{"1"}}, s, CrawlOptions{Dashboards: []string{"blocking"}, SummariesOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Tabs != 1 || stats.Cells != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if got := count(t, s, "SELECT COUNT(*) FROM tab_summaries WHERE overall_status = 'FLAKY'"); got != 1 {
+		t.Errorf("expected summary to be written, got %d", got)
+	}
+}
+
+func TestLoadTab(t *testing.T) {
+	ctx := context.Background()
+	s, err := Open(ctx, filepath.Join(t.TempDir(), "testgrid.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	for _, builds := range [][]string{{"9", "8"}, {"10", "9"}} {
+		if _, err := Crawl(ctx, fakeAPI{builds: builds}, s, CrawlOptions{Dashboards: []string{"blocking"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	headers, rows, err := s.LoadTab(ctx, "blocking", "kind-master")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var builds []string
+	for _, h := range headers {
+		builds = append(builds, h.Build)
+	}
+	if strings.Join(builds, ",") != "10,9,8" || len(headers[0].Extra) != 1 {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+	// The flaky test had not run in build 9 when it was newest, but had
+	// failed by the next export
+	if len(rows) != 2 || rows[1].Name != "flaky" {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	var results []string
+	for _, c := range rows[1].Cells {
+		results = append(results, client.CellResultString(c.Result))
+	}
+	if got := strings.Join(results, ","); got != "EMPTY,FAIL,FAIL" || rows[1].Cells[1].Message != "timed out" {
+		t.Errorf("unexpected cells: %s %+v", got, rows[1].Cells)
+	}
+
+	if _, _, err := s.LoadTab(ctx, "blocking", "missing"); err == nil {
+		t.Error("expected an error for an unknown tab")
+	}
+}
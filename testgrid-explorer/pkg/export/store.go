@@ -0,0 +1,376 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the normalized tables
+//
+// Natural keys are unique so every write is an upsert and repeated exports
+// extend the same database instead of duplicating rows.
+const schema = `
+CREATE TABLE IF NOT EXISTS groups (
+	name TEXT PRIMARY KEY,
+	link TEXT
+);
+
+CREATE TABLE IF NOT EXISTS dashboards (
+	name       TEXT PRIMARY KEY,
+	group_name TEXT REFERENCES groups(name),
+	link       TEXT
+);
+
+CREATE TABLE IF NOT EXISTS tabs (
+	id             INTEGER PRIMARY KEY,
+	dashboard_name TEXT NOT NULL REFERENCES dashboards(name),
+	name           TEXT NOT NULL,
+	link           TEXT,
+	UNIQUE (dashboard_name, name)
+);
+
+CREATE TABLE IF NOT EXISTS tab_summaries (
+	tab_id                  INTEGER NOT NULL REFERENCES tabs(id),
+	last_update_timestamp   TEXT NOT NULL,
+	overall_status          TEXT,
+	detailed_status_message TEXT,
+	last_run_timestamp      TEXT,
+	latest_passing_build    TEXT,
+	fetched_at              TEXT NOT NULL,
+	PRIMARY KEY (tab_id, last_update_timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS builds (
+	id      INTEGER PRIMARY KEY,
+	tab_id  INTEGER NOT NULL REFERENCES tabs(id),
+	build   TEXT NOT NULL,
+	started TEXT,
+	extra   TEXT,
+	UNIQUE (tab_id, build)
+);
+
+CREATE TABLE IF NOT EXISTS tests (
+	id     INTEGER PRIMARY KEY,
+	tab_id INTEGER NOT NULL REFERENCES tabs(id),
+	name   TEXT NOT NULL,
+	UNIQUE (tab_id, name)
+);
+
+-- results names the known cell result codes for joins
+CREATE TABLE IF NOT EXISTS results (
+	code INTEGER PRIMARY KEY,
+	name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cells (
+	build_id INTEGER NOT NULL REFERENCES builds(id),
+	test_id  INTEGER NOT NULL REFERENCES tests(id),
+	result   INTEGER NOT NULL,
+	message  TEXT,
+	icon     TEXT,
+	PRIMARY KEY (build_id, test_id)
+);
+
+CREATE INDEX IF NOT EXISTS cells_test_id ON cells(test_id);
+`
+
+// Store writes TestGrid data to a SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the SQLite database at path and ensures the schema exists
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	// SQLite allows a single writer; serializing on one connection avoids
+	// SQLITE_BUSY errors when tabs are written from several goroutines
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA foreign_keys = ON",
+		schema,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initializing database: %w", err)
+		}
+	}
+
+	for _, code := range []int{client.CellResultEmpty, client.CellResultPass, client.CellResultFail, client.CellResultSkipped, client.CellResultTruncated} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO results (code, name) VALUES (?, ?) ON CONFLICT (code) DO NOTHING`,
+			code, client.CellResultString(code)); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initializing database: %w", err)
+		}
+	}
+	return &Store{db: db}, nil
+}
+
+// DB returns the underlying database handle
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// UpsertGroup records a dashboard group
+func (s *Store) UpsertGroup(ctx context.Context, g client.DashboardGroup) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO groups (name, link) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET link = excluded.link`,
+		g.Name, g.Link)
+	if err != nil {
+		return fmt.Errorf("writing group %s: %w", g.Name, err)
+	}
+	return nil
+}
+
+// UpsertDashboard records a dashboard, keeping a previously known group and
+// link if they are empty
+func (s *Store) UpsertDashboard(ctx context.Context, d client.Dashboard, group string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO dashboards (name, group_name, link) VALUES (?, NULLIF(?, ''), NULLIF(?, ''))
+		ON CONFLICT (name) DO UPDATE SET
+			group_name = COALESCE(excluded.group_name, dashboards.group_name),
+			link = COALESCE(excluded.link, dashboards.link)`,
+		d.Name, group, d.Link)
+	if err != nil {
+		return fmt.Errorf("writing dashboard %s: %w", d.Name, err)
+	}
+	return nil
+}
+
+// Tab is everything exported for a single tab
+type Tab struct {
+	Dashboard string
+	Tab       client.DashboardTab
+	Summary   *client.TabSummary
+	Headers   []client.Header
+	Rows      []client.Row
+}
+
+// TabCounts is the number of rows written for a tab
+type TabCounts struct {
+	Builds int
+	Tests  int
+	Cells  int
+}
+
+// WriteTab upserts a tab with its summary, builds, tests and non-empty cells
+// in a single transaction
+func (s *Store) WriteTab(ctx context.Context, t Tab, fetchedAt time.Time) (TabCounts, error) {
+	var counts TabCounts
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return counts, fmt.Errorf("writing tab %s/%s: %w", t.Dashboard, t.Tab.Name, err)
+	}
+	defer tx.Rollback()
+
+	counts, err = writeTab(ctx, tx, t, fetchedAt)
+	if err != nil {
+		return counts, fmt.Errorf("writing tab %s/%s: %w", t.Dashboard, t.Tab.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return counts, fmt.Errorf("writing tab %s/%s: %w", t.Dashboard, t.Tab.Name, err)
+	}
+	return counts, nil
+}
+
+func writeTab(ctx context.Context, tx *sql.Tx, t Tab, fetchedAt time.Time) (TabCounts, error) {
+	var counts TabCounts
+
+	var tabID int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO tabs (dashboard_name, name, link) VALUES (?, ?, NULLIF(?, ''))
+		ON CONFLICT (dashboard_name, name) DO UPDATE SET link = COALESCE(excluded.link, tabs.link)
+		RETURNING id`,
+		t.Dashboard, t.Tab.Name, t.Tab.Link).Scan(&tabID)
+	if err != nil {
+		return counts, err
+	}
+
+	if t.Summary != nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tab_summaries (tab_id, last_update_timestamp, overall_status, detailed_status_message,
+				last_run_timestamp, latest_passing_build, fetched_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (tab_id, last_update_timestamp) DO UPDATE SET
+				overall_status = excluded.overall_status,
+				detailed_status_message = excluded.detailed_status_message,
+				last_run_timestamp = excluded.last_run_timestamp,
+				latest_passing_build = excluded.latest_passing_build,
+				fetched_at = excluded.fetched_at`,
+			tabID, t.Summary.LastUpdateTimestamp, t.Summary.OverallStatus, t.Summary.DetailedStatusMessage,
+			t.Summary.LastRunTimestamp, t.Summary.LatestPassingBuild, fetchedAt.UTC().Format(time.RFC3339))
+		if err != nil {
+			return counts, err
+		}
+	}
+
+	buildStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO builds (tab_id, build, started, extra) VALUES (?, ?, NULLIF(?, ''), ?)
+		ON CONFLICT (tab_id, build) DO UPDATE SET started = excluded.started, extra = excluded.extra
+		RETURNING id`)
+	if err != nil {
+		return counts, err
+	}
+	defer buildStmt.Close()
+
+	buildIDs := make([]int64, len(t.Headers))
+	for i, h := range t.Headers {
+		var extra any
+		if len(h.Extra) > 0 {
+			b, err := json.Marshal(h.Extra)
+			if err != nil {
+				return counts, err
+			}
+			extra = string(b)
+		}
+		if err := buildStmt.QueryRowContext(ctx, tabID, h.Build, h.Started, extra).Scan(&buildIDs[i]); err != nil {
+			return counts, fmt.Errorf("build %s: %w", h.Build, err)
+		}
+		counts.Builds++
+	}
+
+	testStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tests (tab_id, name) VALUES (?, ?)
+		ON CONFLICT (tab_id, name) DO UPDATE SET name = excluded.name
+		RETURNING id`)
+	if err != nil {
+		return counts, err
+	}
+	defer testStmt.Close()
+
+	cellStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO cells (build_id, test_id, result, message, icon) VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+		ON CONFLICT (build_id, test_id) DO UPDATE SET
+			result = excluded.result, message = excluded.message, icon = excluded.icon`)
+	if err != nil {
+		return counts, err
+	}
+	defer cellStmt.Close()
+
+	for _, r := range t.Rows {
+		var testID int64
+		if err := testStmt.QueryRowContext(ctx, tabID, r.Name).Scan(&testID); err != nil {
+			return counts, fmt.Errorf("test %s: %w", r.Name, err)
+		}
+		counts.Tests++
+
+		for i, c := range r.Cells {
+			// Empty cells mean the test did not run and would dominate the table
+			if i >= len(buildIDs) || c.Result == client.CellResultEmpty {
+				continue
+			}
+			if _, err := cellStmt.ExecContext(ctx, buildIDs[i], testID, c.Result, c.Message, c.Icon); err != nil {
+				return counts, fmt.Errorf("cell %s/%s: %w", t.Headers[i].Build, r.Name, err)
+			}
+			counts.Cells++
+		}
+	}
+	return counts, nil
+}
+
+// LoadTab reads a tab back as a grid, with headers and cells most recent
+// first like the API returns them
+//
+// Builds are ordered by start time, then by build id. Cells that were not
+// stored are empty.
+func (s *Store) LoadTab(ctx context.Context, dashboard, tab string) ([]client.Header, []client.Row, error) {
+	var tabID int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM tabs WHERE dashboard_name = ? AND name = ?`, dashboard, tab).Scan(&tabID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("tab %s/%s is not in the database", dashboard, tab)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading tab %s/%s: %w", dashboard, tab, err)
+	}
+
+	headers, columns, err := loadBuilds(ctx, s.db, tabID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading builds of %s/%s: %w", dashboard, tab, err)
+	}
+	rows, err := loadRows(ctx, s.db, tabID, columns, len(headers))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading cells of %s/%s: %w", dashboard, tab, err)
+	}
+	return headers, rows, nil
+}
+
+// loadBuilds returns the headers of a tab and the column of each build id
+func loadBuilds(ctx context.Context, db *sql.DB, tabID int64) ([]client.Header, map[int64]int, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, build, COALESCE(started, ''), extra FROM builds WHERE tab_id = ?
+		ORDER BY started IS NULL, started DESC, length(build) DESC, build DESC`, tabID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var headers []client.Header
+	columns := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var h client.Header
+		var extra sql.NullString
+		if err := rows.Scan(&id, &h.Build, &h.Started, &extra); err != nil {
+			return nil, nil, err
+		}
+		if extra.Valid {
+			if err := json.Unmarshal([]byte(extra.String), &h.Extra); err != nil {
+				return nil, nil, fmt.Errorf("build %s: %w", h.Build, err)
+			}
+		}
+		columns[id] = len(headers)
+		headers = append(headers, h)
+	}
+	return headers, columns, rows.Err()
+}
+
+// loadRows returns the tests of a tab in name order with their cells placed
+// in columns
+func loadRows(ctx context.Context, db *sql.DB, tabID int64, columns map[int64]int, width int) ([]client.Row, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.name, c.build_id, c.result, COALESCE(c.message, ''), COALESCE(c.icon, '')
+		FROM tests t LEFT JOIN cells c ON c.test_id = t.id
+		WHERE t.tab_id = ?
+		ORDER BY t.name`, tabID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []client.Row
+	for rows.Next() {
+		var name string
+		var buildID, result sql.NullInt64
+		var cell client.Cell
+		if err := rows.Scan(&name, &buildID, &result, &cell.Message, &cell.Icon); err != nil {
+			return nil, err
+		}
+		if len(out) == 0 || out[len(out)-1].Name != name {
+			out = append(out, client.Row{Name: name, Cells: make([]client.Cell, width)})
+		}
+		if !buildID.Valid {
+			continue
+		}
+		cell.Result = int(result.Int64)
+		out[len(out)-1].Cells[columns[buildID.Int64]] = cell
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,285 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// DefaultConcurrency is the number of dashboards collected at once
+const DefaultConcurrency = 8
+
+// Statuses are the tab statuses always exported for testgrid_tab_status, so
+// that alerts can match on a value of 0 as well as 1
+var Statuses = []string{"PASSING", "FAILING", "FLAKY", "STALE", "BROKEN", "PENDING", "ACCEPTABLE", "UNKNOWN"}
+
+// API is the subset of the TestGrid client used by the exporter
+type API interface {
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+}
+
+// Exporter collects dashboard and tab health into Prometheus metrics
+type Exporter struct {
+	api         API
+	groups      []string
+	dashboards  []string
+	concurrency int
+
+	tabStatus          *metrics.Vec
+	tabLastRun         *metrics.Vec
+	tabLastUpdate      *metrics.Vec
+	tabPassPercent     *metrics.Vec
+	dashboardStatus    *metrics.Vec
+	dashboardTabCounts *metrics.Vec
+	collectSuccess     *metrics.Vec
+	collectDuration    *metrics.Vec
+	collectTimestamp   *metrics.Vec
+	collectErrors      *metrics.Vec
+}
+
+// Option is a functional option for Exporter
+type Option func(*Exporter)
+
+// WithGroups collects every dashboard in the given groups
+func WithGroups(groups ...string) Option {
+	return func(e *Exporter) {
+		e.groups = append(e.groups, groups...)
+	}
+}
+
+// WithDashboards collects the given dashboards
+func WithDashboards(dashboards ...string) Option {
+	return func(e *Exporter) {
+		e.dashboards = append(e.dashboards, dashboards...)
+	}
+}
+
+// WithConcurrency sets how many dashboards are collected at once
+func WithConcurrency(n int) Option {
+	return func(e *Exporter) {
+		e.concurrency = n
+	}
+}
+
+// New creates an Exporter that registers its metrics in r
+func New(api API, r *metrics.Registry, opts ...Option) *Exporter {
+	e := &Exporter{
+		api:         api,
+		concurrency: DefaultConcurrency,
+
+		tabStatus:          r.NewGauge("testgrid_tab_status", "Whether the tab currently has the given overall status (1) or not (0).", "dashboard", "tab", "status"),
+		tabLastRun:         r.NewGauge("testgrid_tab_last_run_timestamp_seconds", "Unix time of the tab's most recent run.", "dashboard", "tab"),
+		tabLastUpdate:      r.NewGauge("testgrid_tab_last_update_timestamp_seconds", "Unix time the tab was last updated by TestGrid.", "dashboard", "tab"),
+		tabPassPercent:     r.NewGauge("testgrid_tab_pass_percentage", "Percentage of recent columns that passed.", "dashboard", "tab"),
+		dashboardStatus:    r.NewGauge("testgrid_dashboard_status", "Whether the dashboard currently has the given overall status (1) or not (0).", "dashboard", "status"),
+		dashboardTabCounts: r.NewGauge("testgrid_dashboard_tab_status_count", "Number of tabs in the dashboard with the given status.", "dashboard", "status"),
+		collectSuccess:     r.NewGauge("testgrid_exporter_collect_success", "Whether the last collection of a dashboard succeeded.", "dashboard"),
+		collectDuration:    r.NewGauge("testgrid_exporter_collect_duration_seconds", "Duration of the last full collection."),
+		collectTimestamp:   r.NewGauge("testgrid_exporter_last_collect_timestamp_seconds", "Unix time the last full collection finished."),
+		collectErrors:      r.NewCounter("testgrid_exporter_collect_errors_total", "Collection errors by dashboard or group.", "target"),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// dashboardData is everything collected for one dashboard
+type dashboardData struct {
+	name    string
+	summary *client.DashboardSummary
+	tabs    []client.TabSummary
+	err     error
+}
+
+// Collect fetches every configured dashboard and replaces the exported values
+//
+// Errors for individual dashboards are counted and returned together, but do
+// not stop the rest from being collected. A dashboard that fails keeps the
+// values of its last successful collection, with collect_success set to 0.
+func (e *Exporter) Collect(ctx context.Context) error {
+	start := time.Now()
+	var errs []error
+
+	dashboards, err := e.resolveDashboards(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	results := make([]dashboardData, len(dashboards))
+	g := parallel.NewGroup(e.concurrency)
+	for i, d := range dashboards {
+		g.Go(func() { results[i] = e.collectDashboard(ctx, d) })
+	}
+	g.Wait()
+
+	// Values are built in staging families and swapped in together, so a
+	// scrape during collection never sees the gauges emptied
+	next := e.staging()
+	for _, d := range results {
+		if d.err != nil {
+			e.carryOver(next, d.name)
+			next.collectSuccess.Set(0, d.name)
+			e.collectErrors.Inc(d.name)
+			errs = append(errs, fmt.Errorf("%s: %w", d.name, d.err))
+			continue
+		}
+		next.collectSuccess.Set(1, d.name)
+		next.record(d)
+	}
+	e.replace(next)
+
+	e.collectDuration.Set(time.Since(start).Seconds())
+	e.collectTimestamp.Set(float64(time.Now().Unix()))
+	return errors.Join(errs...)
+}
+
+// resolveDashboards expands groups into their dashboards, deduplicated
+func (e *Exporter) resolveDashboards(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+
+	var errs []error
+	for _, g := range e.groups {
+		resp, err := e.api.GetGroupDashboards(ctx, g)
+		if err != nil {
+			e.collectErrors.Inc(g)
+			errs = append(errs, fmt.Errorf("group %s: %w", g, err))
+			continue
+		}
+		for _, d := range resp.Dashboards {
+			add(d.Name)
+		}
+	}
+	for _, d := range e.dashboards {
+		add(d)
+	}
+	return out, errors.Join(errs...)
+}
+
+func (e *Exporter) collectDashboard(ctx context.Context, dashboard string) dashboardData {
+	d := dashboardData{name: dashboard}
+
+	summary, err := e.api.GetDashboardSummary(ctx, dashboard)
+	if err != nil {
+		d.err = err
+		return d
+	}
+	d.summary = &summary.DashboardSummary
+
+	tabs, err := e.api.ListTabSummaries(ctx, dashboard)
+	if err != nil {
+		d.err = err
+		return d
+	}
+	d.tabs = tabs.TabSummaries
+	return d
+}
+
+// staging returns an exporter whose per-collection gauges are empty,
+// unregistered copies of e's
+func (e *Exporter) staging() *Exporter {
+	return &Exporter{
+		tabStatus:          e.tabStatus.Staging(),
+		tabLastRun:         e.tabLastRun.Staging(),
+		tabLastUpdate:      e.tabLastUpdate.Staging(),
+		tabPassPercent:     e.tabPassPercent.Staging(),
+		dashboardStatus:    e.dashboardStatus.Staging(),
+		dashboardTabCounts: e.dashboardTabCounts.Staging(),
+		collectSuccess:     e.collectSuccess.Staging(),
+	}
+}
+
+// carryOver copies a dashboard's current values into next
+func (e *Exporter) carryOver(next *Exporter, dashboard string) {
+	e.tabStatus.CopyMatching(next.tabStatus, "dashboard", dashboard)
+	e.tabLastRun.CopyMatching(next.tabLastRun, "dashboard", dashboard)
+	e.tabLastUpdate.CopyMatching(next.tabLastUpdate, "dashboard", dashboard)
+	e.tabPassPercent.CopyMatching(next.tabPassPercent, "dashboard", dashboard)
+	e.dashboardStatus.CopyMatching(next.dashboardStatus, "dashboard", dashboard)
+	e.dashboardTabCounts.CopyMatching(next.dashboardTabCounts, "dashboard", dashboard)
+}
+
+// replace swaps in the per-collection gauges built in next
+func (e *Exporter) replace(next *Exporter) {
+	e.tabStatus.Replace(next.tabStatus)
+	e.tabLastRun.Replace(next.tabLastRun)
+	e.tabLastUpdate.Replace(next.tabLastUpdate)
+	e.tabPassPercent.Replace(next.tabPassPercent)
+	e.dashboardStatus.Replace(next.dashboardStatus)
+	e.dashboardTabCounts.Replace(next.dashboardTabCounts)
+	e.collectSuccess.Replace(next.collectSuccess)
+}
+
+func (e *Exporter) record(d dashboardData) {
+	setStatus(e.dashboardStatus, d.summary.OverallStatus, d.name)
+	for status, count := range d.summary.TabStatusCount {
+		e.dashboardTabCounts.Set(float64(count), d.name, status)
+	}
+
+	for _, t := range d.tabs {
+		setStatus(e.tabStatus, t.OverallStatus, d.name, t.TabName)
+		if ts, err := client.ParseTimestamp(t.LastRunTimestamp); err == nil {
+			e.tabLastRun.Set(float64(ts.Unix()), d.name, t.TabName)
+		}
+		if ts, err := client.ParseTimestamp(t.LastUpdateTimestamp); err == nil {
+			e.tabLastUpdate.Set(float64(ts.Unix()), d.name, t.TabName)
+		}
+		if stats, ok := t.Stats(); ok && stats.TotalColumns > 0 {
+			e.tabPassPercent.Set(stats.PassPercentage, d.name, t.TabName)
+		}
+	}
+}
+
+// setStatus exports 1 for the current status and 0 for every other known status
+func setStatus(v *metrics.Vec, current string, labels ...string) {
+	found := false
+	for _, s := range Statuses {
+		value := 0.0
+		if s == current {
+			value = 1
+			found = true
+		}
+		v.Set(value, append(labels, s)...)
+	}
+	if !found && current != "" {
+		v.Set(1, append(labels, current)...)
+	}
+}
+
+// WriteTextfile atomically writes the registry to path for node_exporter's textfile collector
+func WriteTextfile(r *metrics.Registry, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating textfile: %w", err)
+	}
+	if err := r.WriteText(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing textfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing textfile: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing textfile: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
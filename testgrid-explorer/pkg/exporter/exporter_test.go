@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+)
+
+// fakeAPI serves a small sig-release group
+type fakeAPI struct{}
+
+func (fakeAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	if group != "sig-release" {
+		return nil, errors.New("API error: status 404: not found")
+	}
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{
+		{Name: "sig-release-master-blocking"},
+		{Name: "broken-dashboard"},
+	}}, nil
+}
+
+func (fakeAPI) GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error) {
+	if dashboard == "broken-dashboard" {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	return &client.DashboardSummaryResponse{DashboardSummary: client.DashboardSummary{
+		Name:           dashboard,
+		OverallStatus:  "FLAKY",
+		TabStatusCount: map[string]int{"PASSING": 1, "FLAKY": 1},
+	}}, nil
+}
+
+func (fakeAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{
+		{
+			TabName:               "kind-master",
+			OverallStatus:         "PASSING",
+			DetailedStatusMessage: "Tab stats: 10 of 10 (100.0%) recent columns passed (19211 of 19211 or 100.0% cells)",
+			LastRunTimestamp:      "2026-01-28T18:16:55Z",
+		},
+		{
+			TabName:               "gce-cos-master-default",
+			OverallStatus:         "FLAKY",
+			DetailedStatusMessage: "Tab stats: 7 of 10 (70.0%) recent columns passed (1 tests failed)",
+		},
+	}}, nil
+}
+
+func TestCollect(t *testing.T) {
+	r := metrics.NewRegistry()
+	e := New(fakeAPI{}, r, WithGroups("sig-release"), WithDashboards("sig-release-master-blocking"))
+
+	err := e.Collect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "broken-dashboard") {
+		t.Errorf("expected error for broken dashboard, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`testgrid_tab_status{dashboard="sig-release-master-blocking",tab="kind-master",status="PASSING"} 1`,
+		`testgrid_tab_status{dashboard="sig-release-master-blocking",tab="kind-master",status="FAILING"} 0`,
+		`testgrid_tab_last_run_timestamp_seconds{dashboard="sig-release-master-blocking",tab="kind-master"} 1.769624215e+09`,
+		`testgrid_tab_pass_percentage{dashboard="sig-release-master-blocking",tab="gce-cos-master-default"} 70`,
+		`testgrid_dashboard_tab_status_count{dashboard="sig-release-master-blocking",status="FLAKY"} 1`,
+		`testgrid_exporter_collect_success{dashboard="broken-dashboard"} 0`,
+		`testgrid_exporter_collect_success{dashboard="sig-release-master-blocking"} 1`,
+		`testgrid_exporter_collect_errors_total{target="broken-dashboard"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Count(out, `testgrid_exporter_collect_success{dashboard="sig-release-master-blocking"}`) != 1 {
+		t.Errorf("expected dashboards to be deduplicated, got:\n%s", out)
+	}
+}
+
+// flakyAPI is fakeAPI with an upstream that can be taken down
+type flakyAPI struct {
+	fakeAPI
+	down bool
+}
+
+func (f *flakyAPI) GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error) {
+	if f.down {
+		return nil, errors.New("API error: status 503: unavailable")
+	}
+	return f.fakeAPI.GetDashboardSummary(ctx, dashboard)
+}
+
+func TestCollectKeepsFailedDashboards(t *testing.T) {
+	r := metrics.NewRegistry()
+	api := &flakyAPI{}
+	e := New(api, r, WithDashboards("sig-release-master-blocking"))
+	if err := e.Collect(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	api.down = true
+	if err := e.Collect(context.Background()); err == nil {
+		t.Fatal("expected error while the API is down")
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		`testgrid_tab_status{dashboard="sig-release-master-blocking",tab="kind-master",status="PASSING"} 1`,
+		`testgrid_dashboard_status{dashboard="sig-release-master-blocking",status="FLAKY"} 1`,
+		`testgrid_exporter_collect_success{dashboard="sig-release-master-blocking"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected the last values to be kept with %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCollectUnknownGroup(t *testing.T) {
+	r := metrics.NewRegistry()
+	e := New(fakeAPI{}, r, WithGroups("missing"))
+
+	if err := e.Collect(context.Background()); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.NewGauge("g", "Gauge.").Set(1)
+	path := filepath.Join(t.TempDir(), "testgrid.prom")
+
+	if err := WriteTextfile(r, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), "g 1\n") {
+		t.Errorf("unexpected textfile contents:\n%s", data)
+	}
+}
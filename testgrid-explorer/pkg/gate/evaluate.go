@@ -0,0 +1,204 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Verdict is the outcome of a rule or a whole policy
+type Verdict string
+
+const (
+	VerdictPassed        Verdict = "PASSED"
+	VerdictViolated      Verdict = "VIOLATED"
+	VerdictIndeterminate Verdict = "INDETERMINATE"
+)
+
+// API is the subset of the TestGrid client used to evaluate policies
+type API interface {
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// RuleResult is the verdict for a single rule
+type RuleResult struct {
+	Name       string   `json:"name"`
+	Type       RuleType `json:"type"`
+	Verdict    Verdict  `json:"verdict"`
+	Violations []string `json:"violations,omitempty"`
+	Checked    int      `json:"checked"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Report is the result of evaluating a policy
+type Report struct {
+	Verdict     Verdict      `json:"verdict"`
+	EvaluatedAt time.Time    `json:"evaluated_at"`
+	Rules       []RuleResult `json:"rules"`
+}
+
+// Evaluate checks every rule in p against live data
+//
+// A rule that cannot be evaluated because of an API error is INDETERMINATE.
+// The overall verdict is VIOLATED if any rule is violated, otherwise
+// INDETERMINATE if any rule could not be evaluated, otherwise PASSED.
+func Evaluate(ctx context.Context, api API, p *Policy, now time.Time) *Report {
+	report := &Report{Verdict: VerdictPassed, EvaluatedAt: now}
+
+	for _, rule := range p.Rules {
+		res := evaluateRule(ctx, api, rule, now)
+		report.Rules = append(report.Rules, res)
+
+		switch res.Verdict {
+		case VerdictViolated:
+			report.Verdict = VerdictViolated
+		case VerdictIndeterminate:
+			if report.Verdict == VerdictPassed {
+				report.Verdict = VerdictIndeterminate
+			}
+		}
+	}
+	return report
+}
+
+func evaluateRule(ctx context.Context, api API, rule Rule, now time.Time) RuleResult {
+	res := RuleResult{Name: rule.Name, Type: rule.Type}
+
+	var err error
+	switch rule.Type {
+	case RuleTabStatus, RuleMaxStaleness:
+		err = evaluateTabs(ctx, api, rule, now, &res)
+	case RuleTestPassed:
+		err = evaluateTest(ctx, api, rule, &res)
+	default:
+		err = fmt.Errorf("unknown rule type %q", rule.Type)
+	}
+
+	switch {
+	case err != nil:
+		res.Verdict = VerdictIndeterminate
+		res.Error = err.Error()
+	case len(res.Violations) > 0:
+		res.Verdict = VerdictViolated
+	default:
+		res.Verdict = VerdictPassed
+	}
+	return res
+}
+
+// selectTabs returns the tab summaries a tab-based rule applies to
+func selectTabs(ctx context.Context, api API, rule Rule) ([]client.TabSummary, error) {
+	dashboards := []string{rule.Dashboard}
+	if rule.Group != "" {
+		resp, err := api.GetGroupDashboards(ctx, rule.Group)
+		if err != nil {
+			return nil, fmt.Errorf("listing group %s: %w", rule.Group, err)
+		}
+		dashboards = dashboards[:0]
+		for _, d := range resp.Dashboards {
+			dashboards = append(dashboards, d.Name)
+		}
+	}
+
+	var tabs []client.TabSummary
+	for _, d := range dashboards {
+		resp, err := api.ListTabSummaries(ctx, d)
+		if err != nil {
+			return nil, fmt.Errorf("listing tabs of %s: %w", d, err)
+		}
+		for _, t := range resp.TabSummaries {
+			if t.DashboardName == "" {
+				t.DashboardName = d
+			}
+			if len(rule.Tabs) == 0 || slices.Contains(rule.Tabs, t.TabName) {
+				tabs = append(tabs, t)
+			}
+		}
+	}
+
+	if len(rule.Tabs) > 0 {
+		for _, want := range rule.Tabs {
+			if !slices.ContainsFunc(tabs, func(t client.TabSummary) bool { return t.TabName == want }) {
+				return nil, fmt.Errorf("tab %q not found", want)
+			}
+		}
+	}
+	return tabs, nil
+}
+
+func evaluateTabs(ctx context.Context, api API, rule Rule, now time.Time, res *RuleResult) error {
+	tabs, err := selectTabs(ctx, api, rule)
+	if err != nil {
+		return err
+	}
+	res.Checked = len(tabs)
+
+	for _, t := range tabs {
+		name := t.DashboardName + "/" + t.TabName
+		switch rule.Type {
+		case RuleTabStatus:
+			if !slices.Contains(rule.AllowedStatuses, t.OverallStatus) {
+				res.Violations = append(res.Violations, fmt.Sprintf("%s is %s", name, t.OverallStatus))
+			}
+		case RuleMaxStaleness:
+			lastRun, err := client.ParseTimestamp(t.LastRunTimestamp)
+			if err != nil {
+				res.Violations = append(res.Violations, fmt.Sprintf("%s has no last run time", name))
+				continue
+			}
+			if age := now.Sub(lastRun); age > rule.MaxAge.Duration {
+				res.Violations = append(res.Violations, fmt.Sprintf("%s last ran %s ago (%s)", name, age.Round(time.Minute), t.OverallStatus))
+			}
+		}
+	}
+	return nil
+}
+
+func evaluateTest(ctx context.Context, api API, rule Rule, res *RuleResult) error {
+	resp, err := api.GetTabRows(ctx, rule.Dashboard, rule.Tab)
+	if err != nil {
+		return fmt.Errorf("fetching rows of %s/%s: %w", rule.Dashboard, rule.Tab, err)
+	}
+
+	headers, err := api.GetTabHeaders(ctx, rule.Dashboard, rule.Tab)
+	if err != nil {
+		return fmt.Errorf("fetching headers of %s/%s: %w", rule.Dashboard, rule.Tab, err)
+	}
+
+	idx := slices.IndexFunc(resp.Rows, func(r client.Row) bool { return r.Name == rule.Test })
+	if idx < 0 {
+		res.Violations = append(res.Violations, fmt.Sprintf("test %q not found in %s/%s", rule.Test, rule.Dashboard, rule.Tab))
+		return nil
+	}
+
+	// Columns where the test did not run are skipped, so "last N builds"
+	// means the last N builds that produced a result for this test
+	seen := 0
+	for i, c := range resp.Rows[idx].Cells {
+		if seen == rule.Builds {
+			break
+		}
+		if c.Result == client.CellResultEmpty {
+			continue
+		}
+		seen++
+		if c.Result != client.CellResultPass {
+			build := fmt.Sprintf("column %d", i)
+			if i < len(headers.Headers) {
+				build = "build " + headers.Headers[i].Build
+			}
+			res.Violations = append(res.Violations, fmt.Sprintf("%s is %s", build, client.CellResultString(c.Result)))
+		}
+	}
+	res.Checked = seen
+	if seen < rule.Builds {
+		res.Violations = append(res.Violations, fmt.Sprintf("only %d of %d builds have a result", seen, rule.Builds))
+	}
+	return nil
+}
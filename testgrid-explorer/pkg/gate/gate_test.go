@@ -0,0 +1,135 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+var now = time.Date(2026, 1, 29, 12, 0, 0, 0, time.UTC)
+
+// fakeAPI serves a blocking dashboard with one flaky and one stale tab
+type fakeAPI struct{}
+
+func (fakeAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{{Name: "blocking"}}}, nil
+}
+
+func (fakeAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	if dashboard == "unreachable" {
+		return nil, errors.New("API error: status 503: unavailable")
+	}
+	return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{
+		{TabName: "kind-master", OverallStatus: "PASSING", LastRunTimestamp: "2026-01-29T11:00:00Z"},
+		{TabName: "gce", OverallStatus: "FLAKY", LastRunTimestamp: "2026-01-29T10:00:00Z"},
+		{TabName: "old", OverallStatus: "STALE", LastRunTimestamp: "2026-01-27T10:00:00Z"},
+	}}, nil
+}
+
+func (fakeAPI) GetTabHeaders(ctx context.Context, dashboard, tab string) (*client.HeadersResponse, error) {
+	return &client.HeadersResponse{Headers: []client.Header{{Build: "103"}, {Build: "102"}, {Build: "101"}, {Build: "100"}}}, nil
+}
+
+func (fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	return &client.RowsResponse{Rows: []client.Row{
+		{Name: "always-passes", Cells: []client.Cell{{Result: 1}, {}, {Result: 1}, {Result: 1}}},
+		{Name: "failed-once", Cells: []client.Cell{{Result: 1}, {Result: 2}, {Result: 1}, {Result: 1}}},
+	}}, nil
+}
+
+func mustParse(t *testing.T, policy string) *Policy {
+	t.Helper()
+	p, err := ParsePolicy([]byte(policy))
+	if err != nil {
+		t.Fatalf("unexpected error parsing policy: %v", err)
+	}
+	return p
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		errMsg string
+	}{
+		{"empty", `{"rules": []}`, "no rules"},
+		{"unknown field", `{"rules": [{"type": "tab_status", "dashbaord": "x"}]}`, "unknown field"},
+		{"unknown type", `{"rules": [{"type": "vibes"}]}`, "unknown rule type"},
+		{"no target", `{"rules": [{"type": "tab_status", "allowed_statuses": ["PASSING"]}]}`, "dashboard or group"},
+		{"no statuses", `{"rules": [{"type": "tab_status", "dashboard": "x"}]}`, "allowed_statuses"},
+		{"bad duration", `{"rules": [{"type": "max_staleness", "dashboard": "x", "max_age": "1 day"}]}`, "duration"},
+		{"no test", `{"rules": [{"type": "test_passed", "dashboard": "x", "tab": "y"}]}`, "test are required"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePolicy([]byte(tt.policy))
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestEvaluatePassed(t *testing.T) {
+	p := mustParse(t, `{"rules": [
+		{"type": "tab_status", "dashboard": "blocking", "tabs": ["kind-master", "gce"], "allowed_statuses": ["passing", "flaky"]},
+		{"type": "test_passed", "dashboard": "blocking", "tab": "kind-master", "test": "always-passes", "builds": 3},
+		{"type": "max_staleness", "group": "sig-release", "tabs": ["kind-master"], "max_age": "24h"}
+	]}`)
+
+	report := Evaluate(context.Background(), fakeAPI{}, p, now)
+	if report.Verdict != VerdictPassed {
+		t.Errorf("expected PASSED, got %s: %+v", report.Verdict, report.Rules)
+	}
+	if report.Rules[1].Checked != 3 {
+		t.Errorf("expected empty cells to be skipped and 3 builds checked, got %d", report.Rules[1].Checked)
+	}
+}
+
+func TestEvaluateViolated(t *testing.T) {
+	p := mustParse(t, `{"rules": [
+		{"name": "blocking healthy", "type": "tab_status", "dashboard": "blocking", "allowed_statuses": ["PASSING", "FLAKY"]},
+		{"type": "test_passed", "dashboard": "blocking", "tab": "kind-master", "test": "failed-once", "builds": 3},
+		{"type": "max_staleness", "dashboard": "blocking", "max_age": "24h"},
+		{"type": "tab_status", "dashboard": "unreachable", "allowed_statuses": ["PASSING"]}
+	]}`)
+
+	report := Evaluate(context.Background(), fakeAPI{}, p, now)
+	if report.Verdict != VerdictViolated {
+		t.Fatalf("expected VIOLATED, got %s", report.Verdict)
+	}
+
+	status := report.Rules[0]
+	if status.Name != "blocking healthy" || status.Verdict != VerdictViolated || len(status.Violations) != 1 || status.Violations[0] != "blocking/old is STALE" {
+		t.Errorf("unexpected tab status result: %+v", status)
+	}
+
+	test := report.Rules[1]
+	if test.Verdict != VerdictViolated || test.Violations[0] != "build 102 is FAIL" {
+		t.Errorf("unexpected test result: %+v", test)
+	}
+
+	stale := report.Rules[2]
+	if stale.Verdict != VerdictViolated || !strings.HasPrefix(stale.Violations[0], "blocking/old last ran 50h0m0s ago") {
+		t.Errorf("unexpected staleness result: %+v", stale)
+	}
+
+	if report.Rules[3].Verdict != VerdictIndeterminate || report.Rules[3].Error == "" {
+		t.Errorf("expected API error to be indeterminate, got %+v", report.Rules[3])
+	}
+}
+
+func TestEvaluateIndeterminate(t *testing.T) {
+	p := mustParse(t, `{"rules": [
+		{"type": "tab_status", "dashboard": "blocking", "tabs": ["kind-master"], "allowed_statuses": ["PASSING"]},
+		{"type": "tab_status", "dashboard": "blocking", "tabs": ["missing-tab"], "allowed_statuses": ["PASSING"]}
+	]}`)
+
+	report := Evaluate(context.Background(), fakeAPI{}, p, now)
+	if report.Verdict != VerdictIndeterminate {
+		t.Errorf("expected INDETERMINATE, got %s", report.Verdict)
+	}
+}
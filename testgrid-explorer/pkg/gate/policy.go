@@ -0,0 +1,143 @@
+package gate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RuleType identifies how a rule is evaluated
+type RuleType string
+
+const (
+	// RuleTabStatus requires every selected tab to have one of AllowedStatuses
+	RuleTabStatus RuleType = "tab_status"
+	// RuleTestPassed requires Test to have passed in the last Builds results of Tab
+	RuleTestPassed RuleType = "test_passed"
+	// RuleMaxStaleness requires every selected tab to have run within MaxAge
+	RuleMaxStaleness RuleType = "max_staleness"
+)
+
+// Duration is a time.Duration that unmarshals from strings like "24h"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"24h\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Rule is a single policy check
+//
+// Tab-based rules select tabs from Dashboard, or from every dashboard in
+// Group, optionally restricted to Tabs.
+type Rule struct {
+	Name            string   `json:"name"`
+	Type            RuleType `json:"type"`
+	Group           string   `json:"group,omitempty"`
+	Dashboard       string   `json:"dashboard,omitempty"`
+	Tabs            []string `json:"tabs,omitempty"`
+	AllowedStatuses []string `json:"allowed_statuses,omitempty"`
+	Tab             string   `json:"tab,omitempty"`
+	Test            string   `json:"test,omitempty"`
+	Builds          int      `json:"builds,omitempty"`
+	MaxAge          Duration `json:"max_age,omitzero"`
+}
+
+// Policy is a set of rules loaded from a policy file
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadPolicy reads and validates a JSON policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy decodes and validates a JSON policy
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("decoding policy: %w", err)
+	}
+	if len(p.Rules) == 0 {
+		return nil, fmt.Errorf("policy has no rules")
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &p, nil
+}
+
+func (r *Rule) validate() error {
+	switch r.Type {
+	case RuleTabStatus, RuleMaxStaleness:
+		if (r.Dashboard == "") == (r.Group == "") {
+			return fmt.Errorf("exactly one of dashboard or group is required")
+		}
+		if r.Type == RuleTabStatus && len(r.AllowedStatuses) == 0 {
+			return fmt.Errorf("allowed_statuses is required")
+		}
+		if r.Type == RuleMaxStaleness && r.MaxAge.Duration <= 0 {
+			return fmt.Errorf("max_age is required")
+		}
+		for i, s := range r.AllowedStatuses {
+			r.AllowedStatuses[i] = strings.ToUpper(s)
+		}
+	case RuleTestPassed:
+		if r.Dashboard == "" || r.Tab == "" || r.Test == "" {
+			return fmt.Errorf("dashboard, tab and test are required")
+		}
+		if r.Builds <= 0 {
+			r.Builds = 1
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q", r.Type)
+	}
+
+	if r.Name == "" {
+		r.Name = r.defaultName()
+	}
+	return nil
+}
+
+func (r *Rule) defaultName() string {
+	target := r.Dashboard
+	if target == "" {
+		target = "group " + r.Group
+	}
+	switch r.Type {
+	case RuleTabStatus:
+		return fmt.Sprintf("%s tabs %s", target, strings.Join(r.AllowedStatuses, " or "))
+	case RuleMaxStaleness:
+		return fmt.Sprintf("%s tabs ran within %s", target, r.MaxAge.Duration)
+	default:
+		return fmt.Sprintf("%s passed in last %d builds of %s", r.Test, r.Builds, r.Tab)
+	}
+}
@@ -0,0 +1,186 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// LatestBuild selects the most recent column that has any results
+const LatestBuild = "latest"
+
+// Testsuites is the root element of a JUnit XML document
+type Testsuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Suites   []Testsuite `xml:"testsuite"`
+}
+
+// Testsuite holds the results of a single build
+type Testsuite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Skipped    int        `xml:"skipped,attr"`
+	Timestamp  string     `xml:"timestamp,attr,omitempty"`
+	Properties []Property `xml:"properties>property,omitempty"`
+	Testcases  []Testcase `xml:"testcase"`
+}
+
+// Property is a name/value pair attached to a suite
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Testcase is the result of one test in one build
+type Testcase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+}
+
+// Failure marks a failed testcase
+type Failure struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Skipped marks a skipped testcase
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// SelectColumn resolves a build id, or LatestBuild, to a column index
+func SelectColumn(headers []client.Header, rows []client.Row, build string) (int, error) {
+	if build == "" || build == LatestBuild {
+		for i := range headers {
+			if columnHasResults(rows, i) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("no build has results")
+	}
+
+	for i, h := range headers {
+		if h.Build == build {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("build %q not found", build)
+}
+
+// LatestColumns returns up to n of the most recent columns that have results
+func LatestColumns(headers []client.Header, rows []client.Row, n int) []int {
+	var cols []int
+	for i := range headers {
+		if len(cols) == n {
+			break
+		}
+		if columnHasResults(rows, i) {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+func columnHasResults(rows []client.Row, col int) bool {
+	for _, r := range rows {
+		if col < len(r.Cells) && r.Cells[col].Result != client.CellResultEmpty {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert builds a JUnit document with one suite per selected column
+//
+// Tests that did not run in a build are omitted from its suite.
+func Convert(dashboard, tab string, headers []client.Header, rows []client.Row, cols []int) *Testsuites {
+	doc := &Testsuites{Name: dashboard + "/" + tab}
+
+	for _, col := range cols {
+		var h client.Header
+		if col < len(headers) {
+			h = headers[col]
+		}
+
+		suite := Testsuite{
+			Name: fmt.Sprintf("%s/%s #%s", dashboard, tab, h.Build),
+			Properties: []Property{
+				{Name: "dashboard", Value: dashboard},
+				{Name: "tab", Value: tab},
+				{Name: "build", Value: h.Build},
+			},
+		}
+		if started, err := client.ParseTimestamp(h.Started); err == nil {
+			suite.Timestamp = started.UTC().Format("2006-01-02T15:04:05")
+		}
+		if len(h.Extra) > 0 {
+			suite.Properties = append(suite.Properties, Property{Name: "extra", Value: strings.Join(h.Extra, ",")})
+		}
+
+		for _, r := range rows {
+			if col >= len(r.Cells) || r.Cells[col].Result == client.CellResultEmpty {
+				continue
+			}
+			c := r.Cells[col]
+			tc := Testcase{Name: r.Name, Classname: tab}
+
+			switch c.Result {
+			case client.CellResultPass:
+			case client.CellResultSkipped, client.CellResultTruncated:
+				msg := c.Message
+				if msg == "" && c.Result == client.CellResultTruncated {
+					msg = "result truncated by TestGrid"
+				}
+				tc.Skipped = &Skipped{Message: msg}
+				suite.Skipped++
+			default:
+				tc.Failure = &Failure{
+					Message: firstLine(c.Message),
+					Type:    client.CellResultString(c.Result),
+					Text:    c.Message,
+				}
+				suite.Failures++
+			}
+			suite.Testcases = append(suite.Testcases, tc)
+		}
+		suite.Tests = len(suite.Testcases)
+
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Skipped += suite.Skipped
+		doc.Suites = append(doc.Suites, suite)
+	}
+	return doc
+}
+
+// Write encodes doc as indented XML with a declaration
+func Write(w io.Writer, doc *Testsuites) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding junit: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,116 @@
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+var (
+	headers = []client.Header{
+		{Build: "1003"},
+		{Build: "1002", Started: "2026-01-28T18:16:55Z", Extra: []string{"v1.36.0-alpha.1"}},
+		{Build: "1001"},
+	}
+	rows = []client.Row{
+		{Name: "Overall", Cells: []client.Cell{{}, {Result: 2, Message: "1 test failed"}, {Result: 1}}},
+		{Name: "[sig-node] Pods should run", Cells: []client.Cell{{}, {Result: 1}, {Result: 1}}},
+		{Name: "[sig-storage] CSI should mount", Cells: []client.Cell{{}, {Result: 2, Message: "timed out\nwaiting for volume"}, {}}},
+		{Name: "[sig-apps] Disruptive", Cells: []client.Cell{{}, {Result: 3, Message: "skipped by focus"}, {Result: 3}}},
+	}
+)
+
+func TestSelectColumn(t *testing.T) {
+	tests := []struct {
+		build   string
+		want    int
+		wantErr bool
+	}{
+		{"latest", 1, false},
+		{"", 1, false},
+		{"1001", 2, false},
+		{"1003", 0, false},
+		{"999", -1, true},
+	}
+
+	for _, tt := range tests {
+		got, err := SelectColumn(headers, rows, tt.build)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("SelectColumn(%q) = %d, %v; want %d", tt.build, got, err, tt.want)
+		}
+	}
+
+	if _, err := SelectColumn(headers, nil, LatestBuild); err == nil {
+		t.Error("expected error when no build has results")
+	}
+}
+
+func TestLatestColumns(t *testing.T) {
+	if got := LatestColumns(headers, rows, 5); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("expected columns [1 2], got %v", got)
+	}
+	if got := LatestColumns(headers, rows, 1); len(got) != 1 {
+		t.Errorf("expected 1 column, got %v", got)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	doc := Convert("sig-release-master-blocking", "kind-master", headers, rows, []int{1, 2})
+
+	if len(doc.Suites) != 2 {
+		t.Fatalf("expected 2 suites, got %d", len(doc.Suites))
+	}
+	if doc.Tests != 7 || doc.Failures != 2 || doc.Skipped != 2 {
+		t.Errorf("unexpected totals: tests=%d failures=%d skipped=%d", doc.Tests, doc.Failures, doc.Skipped)
+	}
+
+	s := doc.Suites[0]
+	if s.Name != "sig-release-master-blocking/kind-master #1002" || s.Timestamp != "2026-01-28T18:16:55" {
+		t.Errorf("unexpected suite: %s at %s", s.Name, s.Timestamp)
+	}
+	if s.Tests != 4 || s.Failures != 2 || s.Skipped != 1 {
+		t.Errorf("unexpected suite counts: %+v", s)
+	}
+	if f := s.Testcases[2].Failure; f == nil || f.Message != "timed out" || f.Text != "timed out\nwaiting for volume" || f.Type != "FAIL" {
+		t.Errorf("unexpected failure: %+v", f)
+	}
+	if sk := s.Testcases[3].Skipped; sk == nil || sk.Message != "skipped by focus" {
+		t.Errorf("unexpected skipped: %+v", sk)
+	}
+
+	// The CSI test did not run in build 1001
+	if got := doc.Suites[1].Tests; got != 3 {
+		t.Errorf("expected empty cells to be omitted, got %d tests", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Convert("d", "t", headers, rows, []int{1})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<testsuites name="d/t" tests="4" failures="2" skipped="1">`,
+		`<property name="extra" value="v1.36.0-alpha.1"></property>`,
+		`<testcase name="[sig-node] Pods should run" classname="t"></testcase>`,
+		`<failure message="timed out" type="FAIL">timed out&#xA;waiting for volume</failure>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	var decoded Testsuites
+	if err := xml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if len(decoded.Suites[0].Testcases) != 4 {
+		t.Errorf("expected 4 testcases after round trip, got %d", len(decoded.Suites[0].Testcases))
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// instrumentedClient counts requests made through a client.HTTPClient
+type instrumentedClient struct {
+	next     client.HTTPClient
+	requests *Vec
+	errors   *Vec
+	duration *Vec
+}
+
+// InstrumentHTTPClient wraps hc so every upstream request is recorded in r
+//
+// It registers testgrid_client_requests_total{code},
+// testgrid_client_errors_total and
+// testgrid_client_request_duration_seconds_total.
+func InstrumentHTTPClient(hc client.HTTPClient, r *Registry) client.HTTPClient {
+	return &instrumentedClient{
+		next:     hc,
+		requests: r.NewCounter("testgrid_client_requests_total", "TestGrid API requests by HTTP status code.", "code"),
+		errors:   r.NewCounter("testgrid_client_errors_total", "TestGrid API requests that failed or returned a non-200 status."),
+		duration: r.NewCounter("testgrid_client_request_duration_seconds_total", "Total time spent waiting for TestGrid API responses."),
+	}
+}
+
+func (c *instrumentedClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.next.Do(req)
+	c.duration.Add(time.Since(start).Seconds())
+
+	if err != nil {
+		c.requests.Inc("error")
+		c.errors.Inc()
+		return resp, err
+	}
+
+	c.requests.Inc(strconv.Itoa(resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		c.errors.Inc()
+	}
+	return resp, nil
+}
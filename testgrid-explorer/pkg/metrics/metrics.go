@@ -0,0 +1,238 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is a Prometheus metric type
+type Type string
+
+const (
+	TypeCounter Type = "counter"
+	TypeGauge   Type = "gauge"
+)
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.Mutex
+	families []*Vec
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounter registers a counter family with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Vec {
+	return r.register(name, help, TypeCounter, labels)
+}
+
+// NewGauge registers a gauge family with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Vec {
+	return r.register(name, help, TypeGauge, labels)
+}
+
+func (r *Registry) register(name, help string, typ Type, labels []string) *Vec {
+	v := &Vec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		series: make(map[string]*series),
+	}
+	r.mu.Lock()
+	r.families = append(r.families, v)
+	r.mu.Unlock()
+	return v
+}
+
+// WriteText writes every registered family in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]*Vec(nil), r.families...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, v := range families {
+		v.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an HTTP handler serving the registry
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// series is a single labelled value within a family
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// Vec is a metric family partitioned by label values
+type Vec struct {
+	name   string
+	help   string
+	typ    Type
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// Inc adds one to the series identified by labelValues
+func (v *Vec) Inc(labelValues ...string) {
+	v.Add(1, labelValues...)
+}
+
+// Add adds delta to the series identified by labelValues
+func (v *Vec) Add(delta float64, labelValues ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(labelValues).value += delta
+}
+
+// Set sets the series identified by labelValues to value
+func (v *Vec) Set(value float64, labelValues ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(labelValues).value = value
+}
+
+// Value returns the current value of the series identified by labelValues
+func (v *Vec) Value(labelValues ...string) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.series[seriesKey(labelValues)]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// Reset removes every series, so values for vanished label sets are not exported
+func (v *Vec) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.series = make(map[string]*series)
+}
+
+// Staging returns an empty, unregistered family with the same name and
+// labels, for building values that are later swapped in with Replace
+func (v *Vec) Staging() *Vec {
+	return &Vec{name: v.name, help: v.help, typ: v.typ, labels: v.labels, series: make(map[string]*series)}
+}
+
+// Replace swaps in every series of src at once and leaves src empty, so a
+// concurrent scrape sees either the old or the new values, never a mix
+func (v *Vec) Replace(src *Vec) {
+	src.mu.Lock()
+	s := src.series
+	src.series = make(map[string]*series)
+	src.mu.Unlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.series = s
+}
+
+// CopyMatching copies the series of v whose label has the given value into
+// dst, which must have the same labels
+func (v *Vec) CopyMatching(dst *Vec, label, value string) {
+	i := slices.Index(v.labels, label)
+	if i < 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	for _, s := range v.series {
+		if s.labelValues[i] == value {
+			dst.get(s.labelValues).value = s.value
+		}
+	}
+}
+
+func (v *Vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := seriesKey(labelValues)
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *Vec) writeText(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := v.series[k]
+		fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labelValues), formatValue(s.value))
+	}
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(values[i]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	status := r.NewGauge("testgrid_tab_status", "Tab status.", "dashboard", "tab")
+	total := r.NewCounter("requests_total", "Requests.")
+
+	status.Set(1, "d", `tab "quoted"`)
+	status.Set(0, "a", "b")
+	total.Inc()
+	total.Add(2)
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# HELP testgrid_tab_status Tab status.
+# TYPE testgrid_tab_status gauge
+testgrid_tab_status{dashboard="a",tab="b"} 0
+testgrid_tab_status{dashboard="d",tab="tab \"quoted\""} 1
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total 3
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("g", "Gauge.", "l")
+	g.Set(5, "x")
+	g.Reset()
+
+	if g.Value("x") != 0 {
+		t.Errorf("expected reset value 0, got %v", g.Value("x"))
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if strings.Contains(buf.String(), `l="x"`) {
+		t.Errorf("expected series to be removed, got:\n%s", buf.String())
+	}
+}
+
+func TestReplace(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("g", "Gauge.", "l")
+	g.Set(5, "x")
+
+	next := g.Staging()
+	next.Set(7, "y")
+	if g.Value("x") != 5 || g.Value("y") != 0 {
+		t.Errorf("expected staging values to stay out of the registered family")
+	}
+
+	g.Replace(next)
+	if g.Value("x") != 0 || g.Value("y") != 7 {
+		t.Errorf("expected x=0 y=7 after replace, got x=%v y=%v", g.Value("x"), g.Value("y"))
+	}
+	if next.Value("y") != 0 {
+		t.Errorf("expected staging family to be emptied, got %v", next.Value("y"))
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if strings.Count(buf.String(), "# TYPE g gauge") != 1 {
+		t.Errorf("expected staging family to stay unregistered, got:\n%s", buf.String())
+	}
+}
+
+func TestCopyMatching(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("g", "Gauge.", "dashboard", "tab")
+	g.Set(1, "a", "x")
+	g.Set(2, "b", "x")
+
+	next := g.Staging()
+	g.CopyMatching(next, "dashboard", "a")
+	if next.Value("a", "x") != 1 || next.Value("b", "x") != 0 {
+		t.Errorf("expected only dashboard a to be copied, got a=%v b=%v", next.Value("a", "x"), next.Value("b", "x"))
+	}
+}
+
+// stubHTTPClient returns a fixed response or error
+type stubHTTPClient struct {
+	status int
+	err    error
+}
+
+func (s *stubHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &http.Response{StatusCode: s.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func TestInstrumentHTTPClient(t *testing.T) {
+	r := NewRegistry()
+	stub := &stubHTTPClient{status: http.StatusOK}
+	hc := InstrumentHTTPClient(stub, r)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	hc.Do(req)
+	stub.status = http.StatusNotFound
+	hc.Do(req)
+	stub.err = errors.New("boom")
+	hc.Do(req)
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`testgrid_client_requests_total{code="200"} 1`,
+		`testgrid_client_requests_total{code="404"} 1`,
+		`testgrid_client_requests_total{code="error"} 1`,
+		`testgrid_client_errors_total 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
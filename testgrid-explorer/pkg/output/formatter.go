@@ -61,9 +61,9 @@ func PrintRow(w io.Writer, values ...string) {
 // StatusColor returns ANSI color code for a status
 func StatusColor(status string) string {
 	switch strings.ToUpper(status) {
-	case "PASSING", "PASS":
+	case "PASSING", "PASS", "PASSED":
 		return "\033[32m" // Green
-	case "FAILING", "FAIL":
+	case "FAILING", "FAIL", "VIOLATED":
 		return "\033[31m" // Red
 	case "FLAKY":
 		return "\033[33m" // Yellow
@@ -0,0 +1,35 @@
+package parallel
+
+import "sync"
+
+// Group runs functions in goroutines, at most a fixed number at once
+type Group struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// NewGroup creates a group running at most limit functions at once, or one
+// at a time if limit is not positive
+func NewGroup(limit int) *Group {
+	return &Group{sem: make(chan struct{}, max(limit, 1))}
+}
+
+// Go runs f in a new goroutine once fewer than the limit are running
+//
+// Go does not block, so a running function may call it to start more work
+// without holding up its own slot.
+func (g *Group) Go(f func()) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.sem <- struct{}{}
+		defer func() { <-g.sem }()
+		f()
+	}()
+}
+
+// Wait blocks until every function started with Go, including those started
+// by other functions of the group, has returned
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
@@ -0,0 +1,49 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGroupLimit(t *testing.T) {
+	g := NewGroup(2)
+	var mu sync.Mutex
+	running, peak, done := 0, 0, 0
+	work := func() {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		mu.Lock()
+		running--
+		done++
+		mu.Unlock()
+	}
+
+	for range 10 {
+		g.Go(func() {
+			work()
+			// Nested work is waited for as well
+			g.Go(work)
+		})
+	}
+	g.Wait()
+
+	if done != 20 {
+		t.Errorf("expected 20 functions to run, got %d", done)
+	}
+	if peak > 2 {
+		t.Errorf("expected at most 2 functions at once, got %d", peak)
+	}
+}
+
+func TestGroupNonPositiveLimit(t *testing.T) {
+	g := NewGroup(0)
+	ran := false
+	g.Go(func() { ran = true })
+	g.Wait()
+	if !ran {
+		t.Error("expected function to run with a limit of 0")
+	}
+}
@@ -0,0 +1,218 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// DefaultMinors is the number of supported minor releases shown with master
+const DefaultMinors = 3
+
+// Release dashboard kinds
+const (
+	Blocking  = "blocking"
+	Informing = "informing"
+)
+
+// MasterBranch is the version of the development branch
+const MasterBranch = "master"
+
+// releaseDashboardRe matches sig-release-<version>-<kind> dashboards
+var releaseDashboardRe = regexp.MustCompile(`^sig-release-(master|\d+\.\d+)-(blocking|informing)$`)
+
+// ReleaseAPI is the subset of the TestGrid client used to gather release status
+type ReleaseAPI interface {
+	ListDashboards(ctx context.Context) (*client.DashboardsResponse, error)
+	GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+}
+
+// ReleaseOptions controls which release branches are gathered
+type ReleaseOptions struct {
+	// Version selects a single branch, e.g. "1.34", "v1.34" or "master"
+	Version string
+	// Minors is the number of most recent minor releases shown with master
+	// when no Version is given
+	Minors      int
+	Concurrency int
+	Now         time.Time
+}
+
+// ReleaseBranch is the readiness of one release branch
+type ReleaseBranch struct {
+	Version string `json:"version"`
+	// Status is the blocking dashboard's overall status
+	Status string `json:"status"`
+	// Ready is set when every blocking tab is passing
+	Ready     bool       `json:"ready"`
+	Blocking  *Dashboard `json:"blocking,omitempty"`
+	Informing *Dashboard `json:"informing,omitempty"`
+	// NotPassing are the blocking tabs that are not passing
+	NotPassing []client.TabSummary `json:"not_passing"`
+}
+
+// ReleaseData is the readiness of every selected release branch
+type ReleaseData struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Branches    []ReleaseBranch `json:"branches"`
+	Errors      []string        `json:"errors,omitempty"`
+}
+
+// ParseReleaseDashboard returns the version and kind of a
+// sig-release-<version>-<blocking|informing> dashboard
+func ParseReleaseDashboard(name string) (version, kind string, ok bool) {
+	m := releaseDashboardRe.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// NormalizeVersion accepts "1.34", "v1.34", "release-1.34" and "master"
+func NormalizeVersion(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	v = strings.TrimPrefix(v, "release-")
+	return strings.TrimPrefix(v, "v")
+}
+
+// GatherRelease discovers the release dashboards by name and summarizes the
+// blocking and informing dashboards of each branch
+//
+// Without opts.Version, master and the opts.Minors most recent minor
+// releases are gathered. Branches are ordered master first, then newest
+// first. Dashboards that cannot be fetched are listed in Errors; an error is
+// returned only if no branch matches.
+func GatherRelease(ctx context.Context, api ReleaseAPI, opts ReleaseOptions) (*ReleaseData, error) {
+	o := withDefaults(Options{Concurrency: opts.Concurrency, Now: opts.Now})
+	if opts.Minors <= 0 {
+		opts.Minors = DefaultMinors
+	}
+
+	resp, err := api.ListDashboards(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing dashboards: %w", err)
+	}
+	// dashboards[version][kind] is the dashboard name
+	dashboards := make(map[string]map[string]string)
+	for _, d := range resp.Dashboards {
+		version, kind, ok := ParseReleaseDashboard(d.Name)
+		if !ok {
+			continue
+		}
+		if dashboards[version] == nil {
+			dashboards[version] = make(map[string]string)
+		}
+		dashboards[version][kind] = d.Name
+	}
+
+	versions := make([]string, 0, len(dashboards))
+	for v := range dashboards {
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, compareVersions)
+	if opts.Version != "" {
+		want := NormalizeVersion(opts.Version)
+		if dashboards[want] == nil {
+			return nil, fmt.Errorf("no release dashboards found for %s", opts.Version)
+		}
+		versions = []string{want}
+	} else {
+		minors := 0
+		versions = slices.DeleteFunc(versions, func(v string) bool {
+			if v == MasterBranch {
+				return false
+			}
+			minors++
+			return minors > opts.Minors
+		})
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no sig-release-<version>-blocking or -informing dashboards found")
+	}
+
+	data := &ReleaseData{GeneratedAt: o.Now, Branches: make([]ReleaseBranch, len(versions))}
+	var mu sync.Mutex
+	g := parallel.NewGroup(o.Concurrency)
+	for i, v := range versions {
+		data.Branches[i].Version = v
+		for _, kind := range []string{Blocking, Informing} {
+			name, ok := dashboards[v][kind]
+			if !ok {
+				continue
+			}
+			g.Go(func() {
+				d, err := gatherDashboard(ctx, api, name)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					data.Errors = append(data.Errors, fmt.Sprintf("%s: %v", name, err))
+					return
+				}
+				if kind == Blocking {
+					data.Branches[i].Blocking = d
+				} else {
+					data.Branches[i].Informing = d
+				}
+			})
+		}
+	}
+	g.Wait()
+
+	for i := range data.Branches {
+		assessBranch(&data.Branches[i])
+	}
+	slices.Sort(data.Errors)
+	return data, nil
+}
+
+// assessBranch sets a branch's status and readiness from its blocking tabs
+func assessBranch(b *ReleaseBranch) {
+	b.NotPassing = []client.TabSummary{}
+	if b.Blocking == nil {
+		b.Status = "UNKNOWN"
+		return
+	}
+	b.Status = b.Blocking.OverallStatus
+	for _, t := range b.Blocking.Tabs {
+		if t.OverallStatus != "PASSING" && t.OverallStatus != "ACCEPTABLE" {
+			b.NotPassing = append(b.NotPassing, t)
+		}
+	}
+	slices.SortStableFunc(b.NotPassing, func(x, y client.TabSummary) int {
+		return cmp.Or(client.CompareStatus(x.OverallStatus, y.OverallStatus), cmp.Compare(x.TabName, y.TabName))
+	})
+	b.Ready = len(b.Blocking.Tabs) > 0 && len(b.NotPassing) == 0
+}
+
+// compareVersions orders master first, then minor versions newest first
+func compareVersions(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == MasterBranch:
+		return -1
+	case b == MasterBranch:
+		return 1
+	}
+	ka, kb := minorKey(a), minorKey(b)
+	return cmp.Or(cmp.Compare(kb[0], ka[0]), cmp.Compare(kb[1], ka[1]))
+}
+
+// minorKey returns the major and minor numbers of a version like "1.34"
+func minorKey(v string) [2]int {
+	major, minor, _ := strings.Cut(v, ".")
+	var k [2]int
+	k[0], _ = strconv.Atoi(major)
+	k[1], _ = strconv.Atoi(minor)
+	return k
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Format is the kind of document a template produces
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// builtin maps each format to its embedded template
+var builtin = map[Format]string{
+	FormatMarkdown: "templates/markdown.md.tmpl",
+	FormatHTML:     "templates/html.html.tmpl",
+}
+
+// statusEmoji marks statuses in Markdown, where colors are not available
+var statusEmoji = map[string]string{
+	"PASSING":    "🟢",
+	"ACCEPTABLE": "🟢",
+	"FLAKY":      "🟡",
+	"FAILING":    "🔴",
+	"BROKEN":     "🟣",
+	"STALE":      "⚪",
+	"PENDING":    "⚪",
+}
+
+// ParseFormat parses a format name
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q (supported: markdown, html)", s)
+	}
+}
+
+// FormatForPath guesses the format of a template or output file from its name
+func FormatForPath(path string) Format {
+	if strings.Contains(strings.ToLower(filepath.Base(path)), ".htm") {
+		return FormatHTML
+	}
+	return FormatMarkdown
+}
+
+// Render executes the built-in template for format, or the template file at
+// templatePath if it is set, against data
+//
+// HTML templates are executed with html/template so that test names and
+// messages are escaped.
+func Render(w io.Writer, data *Data, format Format, templatePath string) error {
+	var (
+		name string
+		text []byte
+		err  error
+	)
+	if templatePath != "" {
+		name = filepath.Base(templatePath)
+		text, err = os.ReadFile(templatePath)
+	} else {
+		path, ok := builtin[format]
+		if !ok {
+			return fmt.Errorf("unknown report format %q", format)
+		}
+		name = filepath.Base(path)
+		text, err = templates.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+
+	if format == FormatHTML {
+		t, err := htmltemplate.New(name).Funcs(htmlFuncs).Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("parsing template: %w", err)
+		}
+		return t.Execute(w, data)
+	}
+
+	t, err := template.New(name).Funcs(markdownFuncs).Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	return t.Execute(w, data)
+}
+
+var markdownFuncs = template.FuncMap{
+	"badge": func(status string) string {
+		if e, ok := statusEmoji[status]; ok {
+			return e + " " + status
+		}
+		return status
+	},
+	"truncate": truncate,
+	"percent":  percent,
+	// cell makes text safe inside a Markdown table cell
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+var htmlFuncs = htmltemplate.FuncMap{
+	"badge": func(status string) htmltemplate.HTML {
+		class := "badge badge-" + strings.ToLower(status)
+		return htmltemplate.HTML(fmt.Sprintf(`<span class="%s">%s</span>`,
+			htmltemplate.HTMLEscapeString(class), htmltemplate.HTMLEscapeString(status)))
+	},
+	"truncate": truncate,
+	"percent":  percent,
+	"cell":     func(s string) string { return s },
+}
+
+// percent formats a ratio as a percentage
+func percent(f float64) string {
+	return fmt.Sprintf("%.1f%%", f*100)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
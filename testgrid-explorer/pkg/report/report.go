@@ -0,0 +1,335 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// Defaults for Options
+const (
+	DefaultWindow      = 10
+	DefaultTop         = 10
+	DefaultStaleAfter  = 24 * time.Hour
+	DefaultConcurrency = 8
+)
+
+// API is the subset of the TestGrid client used to gather a report
+type API interface {
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// Options controls what a report gathers
+type Options struct {
+	Group      string
+	Dashboards []string
+	// Window is the number of recent results considered per test
+	Window int
+	// Top limits the failing and flaky test lists
+	Top int
+	// StaleAfter marks tabs that have not run for this long as stale
+	StaleAfter time.Duration
+	// IncludeInfraFailures keeps build columns that look like infra
+	// failures, which are otherwise left out of test rankings
+	IncludeInfraFailures bool
+	Concurrency          int
+	Now                  time.Time
+}
+
+// Dashboard is a dashboard summary with its tabs
+type Dashboard struct {
+	client.DashboardSummary
+	Tabs []client.TabSummary `json:"tabs"`
+}
+
+// Test is a test ranked by its recent failures
+type Test struct {
+	Dashboard string `json:"dashboard"`
+	Tab       string `json:"tab"`
+	Name      string `json:"name"`
+	Failures  int    `json:"failures"`
+	Runs      int    `json:"runs"`
+	// Consecutive is the number of failures since the last pass, see
+	// analysis.ConsecutiveFailures; tests with any are failing
+	Consecutive int    `json:"consecutive"`
+	Message     string `json:"message,omitempty"`
+}
+
+// StaleTab is a tab that is STALE or has not run within Options.StaleAfter
+type StaleTab struct {
+	client.TabSummary
+	LastRun time.Time `json:"last_run,omitzero"`
+	Age     string    `json:"age,omitempty"`
+}
+
+// Data is everything available to report templates
+type Data struct {
+	Group        string         `json:"group,omitempty"`
+	GeneratedAt  time.Time      `json:"generated_at"`
+	Window       int            `json:"window"`
+	Status       string         `json:"status"`
+	StatusCounts map[string]int `json:"status_counts"`
+	Dashboards   []Dashboard    `json:"dashboards"`
+	FailingTests []Test         `json:"failing_tests"`
+	FlakyTests   []Test         `json:"flaky_tests"`
+	StaleTabs    []StaleTab     `json:"stale_tabs"`
+	Errors       []string       `json:"errors,omitempty"`
+}
+
+// Gather fetches summaries for the selected dashboards and rows for every tab
+// that is not passing, and ranks their tests
+//
+// Dashboards that cannot be fetched are listed in Data.Errors; an error is
+// returned only if nothing could be gathered.
+func Gather(ctx context.Context, api API, opts Options) (*Data, error) {
+	opts = withDefaults(opts)
+	data := &Data{Group: opts.Group, GeneratedAt: opts.Now, Window: opts.Window, StatusCounts: make(map[string]int)}
+
+	names, err := selectDashboards(ctx, api, opts.Group, opts.Dashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]*Dashboard, len(names))
+	var tests [][]Test
+	var errs []string
+	var mu sync.Mutex
+	g := parallel.NewGroup(opts.Concurrency)
+
+	run := func(f func() ([]Test, error)) {
+		g.Go(func() {
+			t, err := f()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err.Error())
+				return
+			}
+			tests = append(tests, t)
+		})
+	}
+
+	for i, name := range names {
+		run(func() ([]Test, error) {
+			d, err := gatherDashboard(ctx, api, name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			dashboards[i] = d
+			for _, t := range d.Tabs {
+				if t.OverallStatus != "FAILING" && t.OverallStatus != "FLAKY" {
+					continue
+				}
+				run(func() ([]Test, error) {
+					rows, err := api.GetTabRows(ctx, name, t.TabName)
+					if err != nil {
+						return nil, fmt.Errorf("%s/%s: %w", name, t.TabName, err)
+					}
+					return rankTests(name, t.TabName, maskInfra(rows.Rows, opts.IncludeInfraFailures), opts.Window), nil
+				})
+			}
+			return nil, nil
+		})
+	}
+	g.Wait()
+
+	for _, d := range dashboards {
+		if d == nil {
+			continue
+		}
+		data.Dashboards = append(data.Dashboards, *d)
+		data.StatusCounts[d.OverallStatus]++
+		for _, t := range d.Tabs {
+			if st, ok := staleTab(t, opts); ok {
+				data.StaleTabs = append(data.StaleTabs, st)
+			}
+		}
+	}
+	if len(data.Dashboards) == 0 {
+		return nil, fmt.Errorf("no dashboards could be fetched: %s", errs[0])
+	}
+	data.Status = overallStatus(data.Dashboards)
+
+	for _, ts := range tests {
+		for _, t := range ts {
+			if t.Consecutive > 0 {
+				data.FailingTests = append(data.FailingTests, t)
+			} else {
+				data.FlakyTests = append(data.FlakyTests, t)
+			}
+		}
+	}
+	sortTests(data.FailingTests, func(t Test) int { return t.Consecutive })
+	sortTests(data.FlakyTests, func(t Test) int { return t.Failures })
+	data.FailingTests = data.FailingTests[:min(len(data.FailingTests), opts.Top)]
+	data.FlakyTests = data.FlakyTests[:min(len(data.FlakyTests), opts.Top)]
+
+	slices.SortFunc(data.StaleTabs, func(a, b StaleTab) int { return a.LastRun.Compare(b.LastRun) })
+	slices.Sort(errs)
+	data.Errors = errs
+	return data, nil
+}
+
+// maskInfra leaves out infra failure columns unless include is set
+func maskInfra(rows []client.Row, include bool) []client.Row {
+	if include {
+		return rows
+	}
+	return analysis.ExcludeInfraFailures(rows)
+}
+
+// groupAPI lists the dashboards of a group
+type groupAPI interface {
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+}
+
+// selectDashboards returns dashboards followed by the dashboards of group
+// that are not already in it
+func selectDashboards(ctx context.Context, api groupAPI, group string, dashboards []string) ([]string, error) {
+	names := slices.Clone(dashboards)
+	if group != "" {
+		resp, err := api.GetGroupDashboards(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("listing group %s: %w", group, err)
+		}
+		for _, d := range resp.Dashboards {
+			if !slices.Contains(names, d.Name) {
+				names = append(names, d.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no dashboards selected")
+	}
+	return names, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.Top <= 0 {
+		opts.Top = DefaultTop
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = DefaultStaleAfter
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now().UTC()
+	}
+	return opts
+}
+
+// summaryAPI fetches the summaries of a dashboard and its tabs
+type summaryAPI interface {
+	GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error)
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+}
+
+func gatherDashboard(ctx context.Context, api summaryAPI, name string) (*Dashboard, error) {
+	summary, err := api.GetDashboardSummary(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	tabs, err := api.ListTabSummaries(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dashboard{DashboardSummary: summary.DashboardSummary, Tabs: tabs.TabSummaries}
+	if d.Name == "" {
+		d.Name = name
+	}
+	for i := range d.Tabs {
+		if d.Tabs[i].DashboardName == "" {
+			d.Tabs[i].DashboardName = name
+		}
+	}
+	return d, nil
+}
+
+// rankTests returns the tests of a tab that failed within the last window results
+func rankTests(dashboard, tab string, rows []client.Row, window int) []Test {
+	var out []Test
+	for _, r := range rows {
+		// The Overall row repeats the tab status rather than a single test
+		if r.Name == client.OverallRow {
+			continue
+		}
+		t := Test{Dashboard: dashboard, Tab: tab, Name: r.Name, Consecutive: analysis.ConsecutiveFailures(r.Cells, window)}
+		for _, c := range r.Cells {
+			if t.Runs == window {
+				break
+			}
+			switch c.Result {
+			case client.CellResultPass:
+				t.Runs++
+			case client.CellResultFail:
+				t.Runs++
+				t.Failures++
+				if t.Message == "" {
+					t.Message = c.Message
+				}
+			}
+		}
+		if t.Failures > 0 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func sortTests(tests []Test, key func(Test) int) {
+	slices.SortFunc(tests, func(a, b Test) int {
+		return cmp.Or(
+			cmp.Compare(key(b), key(a)),
+			cmp.Compare(b.Failures, a.Failures),
+			cmp.Compare(a.Dashboard, b.Dashboard),
+			cmp.Compare(a.Tab, b.Tab),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+}
+
+func staleTab(t client.TabSummary, opts Options) (StaleTab, bool) {
+	st := StaleTab{TabSummary: t}
+	lastRun, err := client.ParseTimestamp(t.LastRunTimestamp)
+	if err == nil {
+		st.LastRun = lastRun
+		st.Age = opts.Now.Sub(lastRun).Round(time.Minute).String()
+	}
+	if t.OverallStatus == "STALE" || (err == nil && opts.Now.Sub(lastRun) > opts.StaleAfter) {
+		return st, true
+	}
+	return st, false
+}
+
+// overallStatus is the worst dashboard status
+func overallStatus(dashboards []Dashboard) string {
+	status := ""
+	for _, d := range dashboards {
+		if !slices.Contains(client.Statuses, d.OverallStatus) {
+			continue
+		}
+		if status == "" || client.CompareStatus(d.OverallStatus, status) < 0 {
+			status = d.OverallStatus
+		}
+	}
+	if status == "" {
+		return "UNKNOWN"
+	}
+	return status
+}
@@ -0,0 +1,359 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+)
+
+var now = time.Date(2026, 1, 29, 12, 0, 0, 0, time.UTC)
+
+// fakeAPI serves a blocking dashboard with a failing and a flaky tab, an
+// informing dashboard with a stale tab and a dashboard that cannot be fetched
+type fakeAPI struct{}
+
+func (fakeAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{{Name: "blocking"}, {Name: "informing"}, {Name: "broken"}}}, nil
+}
+
+func (fakeAPI) GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error) {
+	switch dashboard {
+	case "blocking":
+		return &client.DashboardSummaryResponse{DashboardSummary: client.DashboardSummary{Name: dashboard, OverallStatus: "FAILING"}}, nil
+	case "informing":
+		return &client.DashboardSummaryResponse{DashboardSummary: client.DashboardSummary{Name: dashboard, OverallStatus: "PASSING"}}, nil
+	}
+	return nil, errors.New("API error: status 500: boom")
+}
+
+func (fakeAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	if dashboard == "informing" {
+		return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{
+			{TabName: "ok", OverallStatus: "PASSING", LastRunTimestamp: "2026-01-29T11:00:00Z"},
+			{TabName: "old", OverallStatus: "PASSING", LastRunTimestamp: "2026-01-27T12:00:00Z"},
+		}}, nil
+	}
+	return &client.TabSummariesResponse{TabSummaries: []client.TabSummary{
+		{TabName: "gce", OverallStatus: "FAILING", DetailedStatusMessage: "1 | 2 tests failed", LastRunTimestamp: "2026-01-29T11:00:00Z"},
+		{TabName: "kind", OverallStatus: "FLAKY", LastRunTimestamp: "2026-01-29T11:00:00Z"},
+	}}, nil
+}
+
+func (fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	pass, fail := client.Cell{Result: client.CellResultPass}, client.Cell{Result: client.CellResultFail, Message: "timed out"}
+	if tab == "gce" {
+		return &client.RowsResponse{Rows: []client.Row{
+			{Name: "Overall", Cells: []client.Cell{fail, fail, fail}},
+			{Name: "<script>broken</script>", Cells: []client.Cell{fail, {}, fail, fail, pass}},
+			{Name: "always-passes", Cells: []client.Cell{pass, pass, pass}},
+		}}, nil
+	}
+	return &client.RowsResponse{Rows: []client.Row{
+		{Name: "flaky-once", Cells: []client.Cell{pass, fail, pass}},
+		{Name: "flaky-twice", Cells: []client.Cell{pass, fail, fail, pass}},
+	}}, nil
+}
+
+func gather(t *testing.T) *Data {
+	t.Helper()
+	data, err := Gather(context.Background(), fakeAPI{}, Options{Group: "sig-release", Now: now, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return data
+}
+
+func TestGather(t *testing.T) {
+	data := gather(t)
+
+	if data.Status != "FAILING" || len(data.Dashboards) != 2 || data.StatusCounts["PASSING"] != 1 {
+		t.Errorf("unexpected summary: status=%s dashboards=%d counts=%v", data.Status, len(data.Dashboards), data.StatusCounts)
+	}
+	if len(data.Errors) != 1 || !strings.HasPrefix(data.Errors[0], "broken:") {
+		t.Errorf("expected error for broken dashboard, got %v", data.Errors)
+	}
+
+	if len(data.FailingTests) != 1 {
+		t.Fatalf("expected 1 failing test (Overall excluded), got %+v", data.FailingTests)
+	}
+	if f := data.FailingTests[0]; f.Consecutive != 3 || f.Failures != 3 || f.Runs != 4 || f.Message != "timed out" {
+		t.Errorf("unexpected failing test: %+v", f)
+	}
+
+	if len(data.FlakyTests) != 2 || data.FlakyTests[0].Name != "flaky-twice" {
+		t.Errorf("expected flaky tests ranked by failures, got %+v", data.FlakyTests)
+	}
+
+	if len(data.StaleTabs) != 1 || data.StaleTabs[0].TabName != "old" || data.StaleTabs[0].Age != "48h0m0s" {
+		t.Errorf("unexpected stale tabs: %+v", data.StaleTabs)
+	}
+}
+
+func TestGatherTop(t *testing.T) {
+	data, err := Gather(context.Background(), fakeAPI{}, Options{Dashboards: []string{"blocking"}, Top: 1, Window: 2, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.FlakyTests) != 1 || data.FlakyTests[0].Runs != 2 {
+		t.Errorf("expected top and window to be applied, got %+v", data.FlakyTests)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, gather(t), FormatMarkdown, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# sig-release TestGrid report",
+		"Overall status: 🔴 FAILING",
+		"### 🔴 FAILING blocking",
+		`| gce | 🔴 FAILING | 2026-01-29T11:00:00Z | 1 \| 2 tests failed |`,
+		"| <script>broken</script> | blocking / gce | 3 | 3 of 4 |",
+		"| informing / old | 🟢 PASSING | 48h0m0s ago |",
+		"- broken: API error",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, gather(t), FormatHTML, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<style>",
+		`<span class="badge badge-failing">FAILING</span> blocking`,
+		"&lt;script&gt;broken&lt;/script&gt;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("expected test names to be escaped")
+	}
+}
+
+func TestRenderUserTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "release.md.tmpl")
+	tmpl := `{{ .Group }}: {{ badge .Status }}{{ range .FailingTests }} {{ truncate .Name 8 }}{{ end }}`
+	if err := os.WriteFile(path, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, gather(t), FormatForPath(path), path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != "sig-release: 🔴 FAILING <scri..." {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	if FormatForPath("release.html.tmpl") != FormatHTML || FormatForPath("report.htm") != FormatHTML || FormatForPath("release.md.tmpl") != FormatMarkdown {
+		t.Error("unexpected format detection")
+	}
+}
+
+// sigAPI serves a SIG Node dashboard whose untagged tests belong to the
+// dashboard's SIG, and a dashboard that cannot be fetched
+type sigAPI struct{}
+
+func (sigAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{{Name: "sig-node-release-blocking"}, {Name: "broken"}}}, nil
+}
+
+func (sigAPI) ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error) {
+	if dashboard == "broken" {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "gce"}, {Name: "kind"}}}, nil
+}
+
+func (sigAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	pass, fail := client.Cell{Result: client.CellResultPass}, client.Cell{Result: client.CellResultFail, Message: "timed out"}
+	return &client.RowsResponse{Rows: []client.Row{
+		{Name: "Overall", Cells: []client.Cell{fail}},
+		{Name: "[sig-storage] CSI should mount [Flaky]", Cells: []client.Cell{fail, fail}},
+		{Name: "[sig-storage] CSI should unmount", Cells: []client.Cell{pass, fail}},
+		{Name: "node-e2e " + tab, Cells: []client.Cell{pass, pass}},
+	}}, nil
+}
+
+func TestGatherSIGs(t *testing.T) {
+	data, err := GatherSIGs(context.Background(), sigAPI{}, SIGOptions{Group: "sig-node", Now: now, Top: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data.Errors) != 1 {
+		t.Errorf("expected error for broken dashboard, got %v", data.Errors)
+	}
+	if len(data.SIGs) != 2 {
+		t.Fatalf("expected storage and node, got %+v", data.SIGs)
+	}
+
+	storage := data.SIGs[0]
+	if storage.SIG != "storage" || storage.Tests != 4 || storage.Failing != 2 || storage.Flaky != 2 || storage.FailingTabs != 2 || storage.Tabs != 2 {
+		t.Errorf("unexpected storage rollup: %+v", storage)
+	}
+	if len(storage.Offenders) != 1 || storage.Offenders[0].Consecutive != 2 {
+		t.Errorf("expected the top failing test as only offender, got %+v", storage.Offenders)
+	}
+	node := data.SIGs[1]
+	if node.SIG != "node" || node.Tests != 2 || node.PassRate != 1 || node.Dashboards != 1 {
+		t.Errorf("expected untagged tests under the dashboard's SIG, got %+v", node)
+	}
+
+	data, err = GatherSIGs(context.Background(), sigAPI{}, SIGOptions{Group: "sig-node", Now: now, Filter: testname.Filter{ExcludeTags: []string{"Flaky"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.SIGs[0].SIG != "storage" || data.SIGs[0].Failing != 0 || data.SIGs[0].Flaky != 2 {
+		t.Errorf("expected [Flaky] tests to be excluded, got %+v", data.SIGs[0])
+	}
+}
+
+func TestRenderSIGs(t *testing.T) {
+	data, err := GatherSIGs(context.Background(), sigAPI{}, SIGOptions{Dashboards: []string{"sig-node-release-blocking"}, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := RenderSIGs(&buf, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# SIG health report",
+		"| storage | 2 | 2 | 2 | 4 | 2 | 25.0% |",
+		"| node | 0 | 0 | 0 | 2 | 2 | 100.0% |",
+		"## storage",
+		"| [sig-storage] CSI should mount [Flaky] | sig-node-release-blocking / gce | 2 | 2 of 2 | timed out |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "## node") {
+		t.Errorf("expected no offenders section for a healthy SIG:\n%s", out)
+	}
+}
+
+// releaseAPI serves master and four minor release dashboards; 1.31 has no
+// informing dashboard and the 1.32 blocking dashboard cannot be fetched
+type releaseAPI struct{}
+
+func (releaseAPI) ListDashboards(ctx context.Context) (*client.DashboardsResponse, error) {
+	var resp client.DashboardsResponse
+	for _, name := range []string{
+		"sig-release-master-blocking", "sig-release-master-informing",
+		"sig-release-1.9-blocking", "sig-release-1.9-informing",
+		"sig-release-1.31-blocking",
+		"sig-release-1.32-blocking", "sig-release-1.32-informing",
+		"sig-release-1.33-blocking", "sig-release-1.33-informing",
+		"sig-release-1.33-upgrade", "sig-node-release-blocking",
+	} {
+		resp.Dashboards = append(resp.Dashboards, client.Dashboard{Name: name})
+	}
+	return &resp, nil
+}
+
+func (releaseAPI) GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error) {
+	if dashboard == "sig-release-1.32-blocking" {
+		return nil, errors.New("API error: status 500: boom")
+	}
+	status := "PASSING"
+	if dashboard == "sig-release-master-blocking" {
+		status = "FAILING"
+	}
+	return &client.DashboardSummaryResponse{DashboardSummary: client.DashboardSummary{Name: dashboard, OverallStatus: status}}, nil
+}
+
+func (releaseAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	tabs := []client.TabSummary{{TabName: "kind", OverallStatus: "PASSING"}}
+	if dashboard == "sig-release-master-blocking" {
+		tabs = append(tabs,
+			client.TabSummary{TabName: "gce-flaky", OverallStatus: "FLAKY"},
+			client.TabSummary{TabName: "gce", OverallStatus: "FAILING"},
+		)
+	}
+	return &client.TabSummariesResponse{TabSummaries: tabs}, nil
+}
+
+func TestGatherRelease(t *testing.T) {
+	data, err := GatherRelease(context.Background(), releaseAPI{}, ReleaseOptions{Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var versions []string
+	for _, b := range data.Branches {
+		versions = append(versions, b.Version)
+	}
+	// 1.9 is older than the three supported minors
+	if got := strings.Join(versions, ","); got != "master,1.33,1.32,1.31" {
+		t.Errorf("unexpected branches: %s", got)
+	}
+	if len(data.Errors) != 1 || !strings.HasPrefix(data.Errors[0], "sig-release-1.32-blocking") {
+		t.Errorf("expected error for the 1.32 blocking dashboard, got %v", data.Errors)
+	}
+
+	master := data.Branches[0]
+	if master.Ready || master.Status != "FAILING" || master.Informing == nil || len(master.NotPassing) != 2 ||
+		master.NotPassing[0].TabName != "gce" || master.NotPassing[1].TabName != "gce-flaky" {
+		t.Errorf("unexpected master branch: %+v", master)
+	}
+	if b := data.Branches[1]; !b.Ready || b.Status != "PASSING" || len(b.NotPassing) != 0 {
+		t.Errorf("expected 1.33 to be ready, got %+v", b)
+	}
+	if b := data.Branches[2]; b.Ready || b.Status != "UNKNOWN" || b.Informing == nil {
+		t.Errorf("expected 1.32 without a blocking dashboard to be unknown, got %+v", b)
+	}
+	if b := data.Branches[3]; !b.Ready || b.Informing != nil {
+		t.Errorf("expected 1.31 to be ready without informing dashboard, got %+v", b)
+	}
+
+	data, err = GatherRelease(context.Background(), releaseAPI{}, ReleaseOptions{Version: "v1.9", Now: now})
+	if err != nil || len(data.Branches) != 1 || data.Branches[0].Version != "1.9" {
+		t.Errorf("expected only 1.9, got %+v, %v", data, err)
+	}
+	if _, err := GatherRelease(context.Background(), releaseAPI{}, ReleaseOptions{Version: "1.99"}); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
+
+func TestParseReleaseDashboard(t *testing.T) {
+	for _, tt := range []struct {
+		name, version, kind string
+		ok                  bool
+	}{
+		{"sig-release-master-blocking", "master", Blocking, true},
+		{"sig-release-1.34-informing", "1.34", Informing, true},
+		{"sig-release-1.34-upgrade", "", "", false},
+		{"sig-node-release-blocking", "", "", false},
+	} {
+		version, kind, ok := ParseReleaseDashboard(tt.name)
+		if version != tt.version || kind != tt.kind || ok != tt.ok {
+			t.Errorf("ParseReleaseDashboard(%q) = %q, %q, %v", tt.name, version, kind, ok)
+		}
+	}
+	if v := NormalizeVersion(" release-1.34"); v != "1.34" {
+		t.Errorf("NormalizeVersion = %q, want 1.34", v)
+	}
+}
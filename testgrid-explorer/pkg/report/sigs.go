@@ -0,0 +1,146 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/testname"
+)
+
+// SIGAPI is the subset of the TestGrid client used to gather a SIG report
+type SIGAPI interface {
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// SIGOptions controls what a SIG report gathers
+type SIGOptions struct {
+	Group      string
+	Dashboards []string
+	// Window is the number of recent results considered per test
+	Window int
+	// Top limits the offenders listed per SIG
+	Top int
+	// Filter selects the tests, and with Filter.SIGs the SIGs, reported
+	Filter testname.Filter
+	// IncludeInfraFailures keeps build columns that look like infra failures
+	IncludeInfraFailures bool
+	Concurrency          int
+	Now                  time.Time
+}
+
+// SIGHealth is the rollup of one SIG's tests
+type SIGHealth struct {
+	SIG        string `json:"sig"`
+	Dashboards int    `json:"dashboards"`
+	Tabs       int    `json:"tabs"`
+	Tests      int    `json:"tests"`
+	// Failing counts tests with consecutive failures since their last pass
+	Failing int `json:"failing"`
+	// Flaky counts tests that failed within the window but passed since
+	Flaky int `json:"flaky"`
+	// FailingTabs counts tabs with at least one failing test of the SIG
+	FailingTabs int `json:"failing_tabs"`
+	// PassRate is the share of passing results over the window, or -1
+	PassRate float64 `json:"pass_rate"`
+	// Offenders are the failing tests, then the flaky ones, each by
+	// consecutive failures
+	Offenders []Test `json:"offenders"`
+}
+
+// SIGData is everything the SIG report template receives
+type SIGData struct {
+	Group       string      `json:"group,omitempty"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Window      int         `json:"window"`
+	SIGs        []SIGHealth `json:"sigs"`
+	Errors      []string    `json:"errors,omitempty"`
+}
+
+// GatherSIGs fetches the rows of every tab of the selected dashboards and
+// rolls their tests up by SIG with analysis.GroupBySIG
+//
+// A test's SIG is taken from its [sig-*] tag, or else from the dashboard
+// name. SIGs are ranked by failing tests, then failing tabs and flaky tests.
+func GatherSIGs(ctx context.Context, api SIGAPI, opts SIGOptions) (*SIGData, error) {
+	o := withDefaults(Options{Window: opts.Window, Top: opts.Top, Concurrency: opts.Concurrency, Now: opts.Now})
+	data := &SIGData{Group: opts.Group, GeneratedAt: o.Now, Window: o.Window}
+
+	names, err := selectDashboards(ctx, api, opts.Group, opts.Dashboards)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []analysis.TabRows
+	fetched := 0
+	for _, d := range names {
+		tabs, tabErrs, err := analysis.FetchDashboardRows(ctx, api, d, o.Concurrency)
+		if err != nil {
+			data.Errors = append(data.Errors, err.Error())
+			continue
+		}
+		for _, e := range tabErrs {
+			data.Errors = append(data.Errors, e.Error())
+		}
+		fetched++
+		for _, t := range tabs {
+			t.Rows = slices.DeleteFunc(maskInfra(t.Rows, opts.IncludeInfraFailures), func(r client.Row) bool {
+				return !opts.Filter.MatchTags(testname.Parse(r.Name))
+			})
+			all = append(all, t)
+		}
+	}
+	if fetched == 0 {
+		return nil, fmt.Errorf("no dashboards could be fetched: %s", data.Errors[0])
+	}
+
+	for _, s := range analysis.GroupBySIG(all, o.Window) {
+		if !opts.Filter.MatchSIG(s.SIG) {
+			continue
+		}
+		h := SIGHealth{
+			SIG:         s.SIG,
+			Dashboards:  s.Dashboards,
+			Tabs:        s.Tabs,
+			Tests:       s.Tests,
+			Failing:     s.Failing,
+			Flaky:       s.Flaky,
+			FailingTabs: s.FailingTabs,
+			PassRate:    s.PassRate,
+		}
+		for _, t := range s.Offenders[:min(len(s.Offenders), o.Top)] {
+			h.Offenders = append(h.Offenders, Test{
+				Dashboard:   t.Dashboard,
+				Tab:         t.Tab,
+				Name:        t.Test,
+				Failures:    t.Failures,
+				Runs:        t.Runs,
+				Consecutive: t.Consecutive,
+				Message:     t.Message,
+			})
+		}
+		data.SIGs = append(data.SIGs, h)
+	}
+	slices.Sort(data.Errors)
+	return data, nil
+}
+
+// RenderSIGs writes the SIG report as Markdown
+func RenderSIGs(w io.Writer, data *SIGData) error {
+	text, err := templates.ReadFile("templates/sigs.md.tmpl")
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+	t, err := template.New("sigs.md.tmpl").Funcs(markdownFuncs).Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	return t.Execute(w, data)
+}
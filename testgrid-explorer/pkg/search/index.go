@@ -0,0 +1,266 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/analysis"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+)
+
+// indexVersion is bumped whenever the index file format changes
+const indexVersion = 1
+
+// Defaults for BuildOptions
+const (
+	DefaultConcurrency = 8
+	DefaultWindow      = 10
+)
+
+// API is the subset of the TestGrid client used to build an index
+type API interface {
+	ListDashboards(ctx context.Context) (*client.DashboardsResponse, error)
+	GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error)
+	ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// Scope is the set of groups and dashboards an index covers; an empty
+// scope covers every dashboard
+type Scope struct {
+	Groups     []string `json:"groups,omitempty"`
+	Dashboards []string `json:"dashboards,omitempty"`
+}
+
+// Equal reports whether s and o select the same groups and dashboards
+func (s Scope) Equal(o Scope) bool {
+	return slices.Equal(sorted(s.Groups), sorted(o.Groups)) && slices.Equal(sorted(s.Dashboards), sorted(o.Dashboards))
+}
+
+func sorted(s []string) []string {
+	s = slices.Clone(s)
+	slices.Sort(s)
+	return slices.Compact(s)
+}
+
+// BuildOptions controls what an index covers
+type BuildOptions struct {
+	Scope
+	Concurrency int
+	// Window is the number of recent results used for pass rates
+	Window int
+	// IncludeInfraFailures keeps columns that look like infra failures,
+	// which are otherwise left out of pass rates
+	IncludeInfraFailures bool
+}
+
+// Entry is one test in one tab
+type Entry struct {
+	Dashboard string `json:"dashboard"`
+	Tab       string `json:"tab"`
+	Test      string `json:"test"`
+	analysis.MatrixCell
+}
+
+// Index is every test row of the crawled tabs, summarized
+type Index struct {
+	Version int       `json:"version"`
+	BuiltAt time.Time `json:"built_at"`
+	Scope   Scope     `json:"scope"`
+	Window  int       `json:"window"`
+	// IncludeInfraFailures records BuildOptions.IncludeInfraFailures
+	IncludeInfraFailures bool    `json:"include_infra_failures,omitempty"`
+	Dashboards           int     `json:"dashboards"`
+	Tabs                 int     `json:"tabs"`
+	Entries              []Entry `json:"entries"`
+	// Errors lists the dashboards and tabs that could not be crawled
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Build crawls the dashboards in opts.Scope and summarizes every test row
+//
+// At most opts.Concurrency tabs are fetched at once. Dashboards and tabs that
+// cannot be fetched are recorded in Index.Errors; an error is returned only
+// if the dashboards cannot be listed or ctx is cancelled.
+func Build(ctx context.Context, api API, opts BuildOptions) (*Index, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	idx := &Index{
+		Version:              indexVersion,
+		BuiltAt:              time.Now().UTC(),
+		Scope:                opts.Scope,
+		Window:               opts.Window,
+		IncludeInfraFailures: opts.IncludeInfraFailures,
+	}
+
+	dashboards, err := listDashboards(ctx, api, opts.Scope, idx)
+	if err != nil {
+		return nil, err
+	}
+	idx.Dashboards = len(dashboards)
+
+	var mu sync.Mutex
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		idx.Errors = append(idx.Errors, err.Error())
+	}
+
+	g := parallel.NewGroup(opts.Concurrency)
+	for _, d := range dashboards {
+		g.Go(func() {
+			tabs, err := api.ListDashboardTabs(ctx, d)
+			if err != nil {
+				fail(fmt.Errorf("%s: %w", d, err))
+				return
+			}
+			for _, t := range tabs.DashboardTabs {
+				g.Go(func() {
+					resp, err := api.GetTabRows(ctx, d, t.Name)
+					if err != nil {
+						fail(fmt.Errorf("%s/%s: %w", d, t.Name, err))
+						return
+					}
+					rows := resp.Rows
+					if !opts.IncludeInfraFailures {
+						rows = analysis.ExcludeInfraFailures(rows)
+					}
+					entries := make([]Entry, 0, len(rows))
+					for _, r := range rows {
+						if r.Name == client.OverallRow {
+							continue
+						}
+						entries = append(entries, Entry{Dashboard: d, Tab: t.Name, Test: r.Name, MatrixCell: analysis.SummarizeCells(r.Cells, opts.Window)})
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+					idx.Tabs++
+					idx.Entries = append(idx.Entries, entries...)
+				})
+			}
+		})
+	}
+	g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(idx.Entries, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Test, b.Test), cmp.Compare(a.Dashboard, b.Dashboard), cmp.Compare(a.Tab, b.Tab))
+	})
+	slices.Sort(idx.Errors)
+	return idx, nil
+}
+
+// listDashboards expands a scope into dashboard names, recording groups
+// that cannot be listed in idx
+func listDashboards(ctx context.Context, api API, scope Scope, idx *Index) ([]string, error) {
+	if len(scope.Groups) == 0 && len(scope.Dashboards) == 0 {
+		resp, err := api.ListDashboards(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing dashboards: %w", err)
+		}
+		names := make([]string, 0, len(resp.Dashboards))
+		for _, d := range resp.Dashboards {
+			names = append(names, d.Name)
+		}
+		return names, nil
+	}
+
+	var names []string
+	for _, g := range scope.Groups {
+		resp, err := api.GetGroupDashboards(ctx, g)
+		if err != nil {
+			idx.Errors = append(idx.Errors, fmt.Sprintf("group %s: %v", g, err))
+			continue
+		}
+		for _, d := range resp.Dashboards {
+			names = append(names, d.Name)
+		}
+	}
+	names = append(names, scope.Dashboards...)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no dashboards found in groups %v", scope.Groups)
+	}
+	return sorted(names), nil
+}
+
+// Find returns the entries whose test name matches re
+func (idx *Index) Find(re *regexp.Regexp) []Entry {
+	var out []Entry
+	for _, e := range idx.Entries {
+		if re.MatchString(e.Test) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Fresh reports whether idx was built with the same options as opts, other
+// than concurrency, no longer than maxAge ago
+func (idx *Index) Fresh(opts BuildOptions, maxAge time.Duration, now time.Time) bool {
+	return idx.Version == indexVersion && idx.Window == opts.Window &&
+		idx.IncludeInfraFailures == opts.IncludeInfraFailures &&
+		idx.Scope.Equal(opts.Scope) && now.Sub(idx.BuiltAt) <= maxAge
+}
+
+// Load reads an index written by Save
+//
+// A missing file is not an error; nil is returned instead.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing index %s: %w", path, err)
+	}
+	return &idx, nil
+}
+
+// Save writes idx to path atomically
+func (idx *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating index directory: %w", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	return nil
+}
+
+// DefaultPath returns the default index location
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "testgrid", "test-index.json")
+}
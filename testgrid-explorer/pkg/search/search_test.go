@@ -0,0 +1,124 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// fakeAPI serves two dashboards; the informing/flaky tab cannot be fetched
+type fakeAPI struct{}
+
+func (fakeAPI) ListDashboards(ctx context.Context) (*client.DashboardsResponse, error) {
+	return &client.DashboardsResponse{Dashboards: []client.Dashboard{{Name: "blocking"}, {Name: "informing"}}}, nil
+}
+
+func (fakeAPI) GetGroupDashboards(ctx context.Context, group string) (*client.GroupDashboardsResponse, error) {
+	return &client.GroupDashboardsResponse{Dashboards: []client.Dashboard{{Name: "blocking"}}}, nil
+}
+
+func (fakeAPI) ListDashboardTabs(ctx context.Context, dashboard string) (*client.TabsResponse, error) {
+	if dashboard == "informing" {
+		return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "gce"}, {Name: "flaky"}}}, nil
+	}
+	return &client.TabsResponse{DashboardTabs: []client.DashboardTab{{Name: "kind"}}}, nil
+}
+
+func (fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	pass := client.Cell{Result: client.CellResultPass}
+	fail := client.Cell{Result: client.CellResultFail, Message: "timed out"}
+	switch tab {
+	case "flaky":
+		return nil, errors.New("API error: status 500: boom")
+	case "gce":
+		return &client.RowsResponse{Rows: []client.Row{
+			{Name: "Overall", Cells: []client.Cell{fail}},
+			{Name: "[sig-storage] CSI Volumes mount", Cells: []client.Cell{pass, fail, pass, pass}},
+		}}, nil
+	}
+	return &client.RowsResponse{Rows: []client.Row{
+		{Name: "[sig-storage] CSI Volumes mount", Cells: []client.Cell{fail, pass}},
+		{Name: "[sig-node] Pods", Cells: []client.Cell{pass}},
+	}}, nil
+}
+
+func TestBuildAndFind(t *testing.T) {
+	idx, err := Build(context.Background(), fakeAPI{}, BuildOptions{Concurrency: 2, Window: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.Dashboards != 2 || idx.Tabs != 2 || len(idx.Errors) != 1 {
+		t.Errorf("expected 2 dashboards, 2 tabs and 1 error, got %d, %d and %v", idx.Dashboards, idx.Tabs, idx.Errors)
+	}
+	if len(idx.Entries) != 3 {
+		t.Fatalf("expected 3 entries (Overall excluded), got %+v", idx.Entries)
+	}
+
+	matches := idx.Find(regexp.MustCompile(`CSI Volumes`))
+	if len(matches) != 2 || matches[0].Dashboard != "blocking" || matches[1].Dashboard != "informing" {
+		t.Fatalf("expected CSI matches in both dashboards, got %+v", matches)
+	}
+	if m := matches[0]; m.Latest != "FAIL" || m.LastFailure != "timed out" || m.PassRate != 0.5 {
+		t.Errorf("unexpected blocking match: %+v", m)
+	}
+	if m := matches[1]; m.Latest != "PASS" || m.Runs != 3 || m.LastFailure != "timed out" {
+		t.Errorf("unexpected informing match: %+v", m)
+	}
+}
+
+func TestBuildScope(t *testing.T) {
+	idx, err := Build(context.Background(), fakeAPI{}, BuildOptions{Scope: Scope{Groups: []string{"sig-release"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if idx.Dashboards != 1 || len(idx.Entries) != 2 {
+		t.Errorf("expected only the group's dashboard, got %d dashboards and %d entries", idx.Dashboards, len(idx.Entries))
+	}
+}
+
+func TestSaveLoadFresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index", "test-index.json")
+
+	idx, err := Load(path)
+	if err != nil || idx != nil {
+		t.Fatalf("expected nil index for a missing file, got %v, %v", idx, err)
+	}
+
+	built, err := Build(context.Background(), fakeAPI{}, BuildOptions{Scope: Scope{Dashboards: []string{"b", "a"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := built.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	idx, err = Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(idx.Entries) != len(built.Entries) {
+		t.Errorf("expected %d entries after loading, got %d", len(built.Entries), len(idx.Entries))
+	}
+
+	now := idx.BuiltAt.Add(time.Hour)
+	opts := BuildOptions{Scope: Scope{Dashboards: []string{"a", "b"}}, Window: DefaultWindow}
+	if !idx.Fresh(opts, 2*time.Hour, now) {
+		t.Error("expected index to be fresh for the same scope in any order")
+	}
+	if idx.Fresh(opts, 30*time.Minute, now) {
+		t.Error("expected index older than max age to be stale")
+	}
+	if idx.Fresh(BuildOptions{Window: DefaultWindow}, 2*time.Hour, now) {
+		t.Error("expected index not to cover a different scope")
+	}
+	if idx.Fresh(BuildOptions{Scope: opts.Scope, Window: 20}, 2*time.Hour, now) {
+		t.Error("expected index not to cover a different window")
+	}
+	if idx.Fresh(BuildOptions{Scope: opts.Scope, Window: DefaultWindow, IncludeInfraFailures: true}, 2*time.Hour, now) {
+		t.Error("expected index without infra failures not to cover a search including them")
+	}
+}
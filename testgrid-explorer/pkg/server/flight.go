@@ -0,0 +1,44 @@
+package server
+
+import "sync"
+
+// call is an in-flight or completed upstream fetch
+type call struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+// flightGroup coalesces concurrent fetches of the same key into one
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do runs fn once for all concurrent callers with the same key
+//
+// shared reports whether the result was produced for another caller.
+func (g *flightGroup) Do(key string, fn func() ([]byte, error)) (body []byte, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.body, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.body, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.body, c.err, false
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/cache"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+)
+
+const (
+	DefaultCacheTTL        = time.Minute
+	DefaultCacheMaxEntries = 1000
+)
+
+// Upstream fetches raw API responses
+type Upstream interface {
+	GetRaw(ctx context.Context, path string, query url.Values) ([]byte, error)
+}
+
+// Server is a caching proxy in front of the TestGrid API
+type Server struct {
+	upstream    Upstream
+	cache       *cache.Cache
+	flight      flightGroup
+	ttl         time.Duration
+	corsOrigins []string
+	registry    *metrics.Registry
+	mux         *http.ServeMux
+	started     time.Time
+
+	requests       *metrics.Vec
+	cacheEntries   *metrics.Vec
+	cacheErrors    *metrics.Vec
+	coalesced      *metrics.Vec
+	upstreamErrors *metrics.Vec
+}
+
+// Option is a functional option for Server
+type Option func(*Server)
+
+// WithCache sets the response cache
+func WithCache(c *cache.Cache) Option {
+	return func(s *Server) {
+		s.cache = c
+	}
+}
+
+// WithCacheTTL sets how long cached responses are served without revalidation
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.ttl = ttl
+	}
+}
+
+// WithCORSOrigins sets the origins allowed to make cross-origin requests
+//
+// Use "*" to allow any origin. No CORS headers are sent when empty.
+func WithCORSOrigins(origins ...string) Option {
+	return func(s *Server) {
+		s.corsOrigins = origins
+	}
+}
+
+// WithRegistry sets the metrics registry served on /metrics
+func WithRegistry(r *metrics.Registry) Option {
+	return func(s *Server) {
+		s.registry = r
+	}
+}
+
+// New creates a new proxy Server
+func New(upstream Upstream, opts ...Option) *Server {
+	s := &Server{
+		upstream: upstream,
+		ttl:      DefaultCacheTTL,
+		started:  time.Now(),
+		mux:      http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cache == nil {
+		s.cache = cache.New(DefaultCacheMaxEntries, "")
+	}
+	if s.registry == nil {
+		s.registry = metrics.NewRegistry()
+	}
+
+	s.requests = s.registry.NewCounter("testgrid_proxy_requests_total", "Proxy requests by cache result and HTTP status code.", "cache", "code")
+	s.cacheEntries = s.registry.NewGauge("testgrid_proxy_cache_entries", "Responses held in the in-memory cache.")
+	s.cacheErrors = s.registry.NewCounter("testgrid_proxy_cache_write_errors_total", "Responses that could not be written to the disk cache.")
+	s.coalesced = s.registry.NewCounter("testgrid_proxy_coalesced_requests_total", "Requests served by joining an identical in-flight upstream request.")
+	s.upstreamErrors = s.registry.NewCounter("testgrid_proxy_upstream_errors_total", "Upstream fetches that failed.")
+
+	s.mux.HandleFunc("/api/v1/", s.handleProxy)
+	s.mux.HandleFunc("/api/proxy/", s.handleProxy)
+	s.mux.HandleFunc("/api/health", s.handleHealth)
+	s.mux.Handle("/metrics", s.registry.Handler())
+	return s
+}
+
+// Handle registers an additional handler on the server's mux
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// Handler returns the HTTP handler with CORS and gzip applied
+func (s *Server) Handler() http.Handler {
+	return s.cors(gzipHandler(s.mux))
+}
+
+func (s *Server) handleProxy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// /api/proxy/* is kept as an alias for the Hono dev proxy
+	path := r.URL.EscapedPath()
+	if rest, ok := strings.CutPrefix(path, "/api/proxy/"); ok {
+		path = "/api/v1/" + rest
+	}
+	query := r.URL.Query()
+	key := path
+	if len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+
+	body, fetchedAt, cached := s.cache.Get(key)
+	result := "hit"
+	if !cached || time.Since(fetchedAt) >= s.ttl {
+		fresh, err, shared := s.flight.Do(key, func() ([]byte, error) {
+			// Detach from the requesting client so a disconnect does not
+			// cancel the fetch for everyone else waiting on it
+			ctx := context.WithoutCancel(r.Context())
+			b, err := s.upstream.GetRaw(ctx, path, query)
+			if err != nil {
+				s.upstreamErrors.Inc()
+				return nil, err
+			}
+			// A disk write failure still leaves the entry in memory
+			if err := s.cache.Set(key, b, time.Now()); err != nil {
+				s.cacheErrors.Inc()
+			}
+			return b, nil
+		})
+		if shared {
+			s.coalesced.Inc()
+		}
+
+		switch {
+		case err == nil:
+			body, fetchedAt, result = fresh, time.Now(), "miss"
+		case cached:
+			result = "stale"
+		default:
+			s.writeUpstreamError(w, err)
+			return
+		}
+	}
+	s.cacheEntries.Set(float64(s.cache.Len()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", strings.ToUpper(result))
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.ttl.Seconds())))
+	w.Header().Set("Last-Modified", fetchedAt.UTC().Format(http.TimeFormat))
+	s.requests.Inc(result, "200")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+func (s *Server) writeUpstreamError(w http.ResponseWriter, err error) {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		s.requests.Inc("miss", fmt.Sprintf("%d", apiErr.StatusCode))
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(apiErr.StatusCode)
+		io.WriteString(w, apiErr.Body)
+		return
+	}
+	s.requests.Inc("miss", "502")
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
+
+// healthResponse is the body of /api/health
+type healthResponse struct {
+	Status        string  `json:"status"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	CacheEntries  int     `json:"cache_entries"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(healthResponse{
+		Status:        "ok",
+		UptimeSeconds: time.Since(s.started).Seconds(),
+		CacheEntries:  s.cache.Len(),
+	})
+}
+
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if allowed := s.allowedOrigin(origin); allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+				if h := r.Header.Get("Access-Control-Request-Headers"); h != "" {
+					w.Header().Set("Access-Control-Allow-Headers", h)
+				}
+				w.Header().Set("Access-Control-Max-Age", "86400")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) allowedOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	for _, o := range s.corsOrigins {
+		if o == "*" {
+			return "*"
+		}
+		if strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// gzipResponseWriter compresses everything written through it
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz *gzip.Writer
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	g.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(code)
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	return g.gz.Write(b)
+}
+
+// Flush lets streaming handlers push compressed data to the client
+func (g *gzipResponseWriter) Flush() {
+	g.gz.Flush()
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func gzipHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		next.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, gz: gz}, r)
+	})
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/cache"
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// fakeUpstream counts fetches and optionally blocks until released
+type fakeUpstream struct {
+	calls   atomic.Int32
+	release chan struct{}
+	err     error
+}
+
+func (f *fakeUpstream) GetRaw(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte(`{"path":"` + path + `"}`), nil
+}
+
+func get(t *testing.T, h http.Handler, target string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProxyCaching(t *testing.T) {
+	up := &fakeUpstream{}
+	s := New(up, WithCacheTTL(time.Hour))
+	h := s.Handler()
+
+	first := get(t, h, "/api/v1/dashboards", nil)
+	if first.Code != http.StatusOK || first.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("expected 200 MISS, got %d %s", first.Code, first.Header().Get("X-Cache"))
+	}
+	if first.Body.String() != `{"path":"/api/v1/dashboards"}` {
+		t.Errorf("unexpected body: %s", first.Body.String())
+	}
+
+	second := get(t, h, "/api/proxy/dashboards", nil)
+	if second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected Hono-style alias to hit the cache, got %s", second.Header().Get("X-Cache"))
+	}
+	if up.calls.Load() != 1 {
+		t.Errorf("expected 1 upstream call, got %d", up.calls.Load())
+	}
+}
+
+func TestProxyStaleOnError(t *testing.T) {
+	up := &fakeUpstream{}
+	s := New(up, WithCacheTTL(0))
+	h := s.Handler()
+
+	get(t, h, "/api/v1/dashboards", nil)
+	up.err = &client.APIError{StatusCode: http.StatusServiceUnavailable, Body: "down"}
+
+	rec := get(t, h, "/api/v1/dashboards", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "STALE" {
+		t.Errorf("expected stale 200, got %d %s", rec.Code, rec.Header().Get("X-Cache"))
+	}
+
+	rec = get(t, h, "/api/v1/dashboards/missing", nil)
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "down" {
+		t.Errorf("expected upstream status to pass through, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestProxyCoalescesConcurrentRequests(t *testing.T) {
+	up := &fakeUpstream{release: make(chan struct{})}
+	s := New(up)
+	h := s.Handler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get(t, h, "/api/v1/dashboard-groups", nil)
+		}()
+	}
+
+	// Give the goroutines time to join the in-flight call
+	time.Sleep(50 * time.Millisecond)
+	close(up.release)
+	wg.Wait()
+
+	if up.calls.Load() != 1 {
+		t.Errorf("expected 1 upstream call, got %d", up.calls.Load())
+	}
+}
+
+func TestProxyDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	up := &fakeUpstream{}
+	get(t, New(up, WithCache(cache.New(10, dir)), WithCacheTTL(time.Hour)).Handler(), "/api/v1/dashboards", nil)
+
+	// A fresh server with an empty memory cache is served from disk
+	rec := get(t, New(up, WithCache(cache.New(10, dir)), WithCacheTTL(time.Hour)).Handler(), "/api/v1/dashboards", nil)
+	if rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected disk cache hit, got %s", rec.Header().Get("X-Cache"))
+	}
+	if up.calls.Load() != 1 {
+		t.Errorf("expected 1 upstream call, got %d", up.calls.Load())
+	}
+}
+
+func TestGzipAndCORS(t *testing.T) {
+	s := New(&fakeUpstream{}, WithCORSOrigins("https://pano.example.com"))
+	h := s.Handler()
+
+	rec := get(t, h, "/api/v1/dashboards", map[string]string{
+		"Accept-Encoding": "gzip",
+		"Origin":          "https://pano.example.com",
+	})
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding")
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://pano.example.com" {
+		t.Errorf("expected CORS origin header, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(gz)
+	if !strings.Contains(string(body), "/api/v1/dashboards") {
+		t.Errorf("unexpected decompressed body: %s", body)
+	}
+
+	rec = get(t, h, "/api/v1/dashboards", map[string]string{"Origin": "https://evil.example.com"})
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected no CORS header for disallowed origin")
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/dashboards", nil)
+	req.Header.Set("Origin", "https://pano.example.com")
+	pre := httptest.NewRecorder()
+	h.ServeHTTP(pre, req)
+	if pre.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for preflight, got %d", pre.Code)
+	}
+}
+
+func TestHealthAndMetrics(t *testing.T) {
+	s := New(&fakeUpstream{})
+	h := s.Handler()
+	get(t, h, "/api/v1/dashboards", nil)
+
+	health := get(t, h, "/api/health", nil)
+	if health.Code != http.StatusOK || !strings.Contains(health.Body.String(), `"status":"ok"`) {
+		t.Errorf("unexpected health response: %d %s", health.Code, health.Body.String())
+	}
+
+	m := get(t, h, "/metrics", nil)
+	if !strings.Contains(m.Body.String(), `testgrid_proxy_requests_total{cache="miss",code="200"} 1`) {
+		t.Errorf("unexpected metrics output:\n%s", m.Body.String())
+	}
+}
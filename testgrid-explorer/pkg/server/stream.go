@@ -0,0 +1,466 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/metrics"
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+)
+
+const (
+	DefaultStreamInterval   = time.Minute
+	DefaultStreamHeartbeat  = 15 * time.Second
+	DefaultStreamBufferSize = 1000
+
+	// clientQueueSize bounds how far a client may lag before it is dropped
+	clientQueueSize = 64
+
+	// streamRetry is the reconnect delay suggested to EventSource clients
+	streamRetry = 5 * time.Second
+)
+
+// Stream event types
+const (
+	StreamEventAdded    = "added"
+	StreamEventStatus   = "status"
+	StreamEventUpdate   = "update"
+	StreamEventRemoved  = "removed"
+	StreamEventSnapshot = "snapshot"
+)
+
+// StreamAPI is the subset of the TestGrid client polled by the stream hub
+type StreamAPI interface {
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error)
+}
+
+// StreamEvent is a change to a tab summary pushed to stream clients
+type StreamEvent struct {
+	ID             uint64             `json:"id,omitempty"`
+	Type           string             `json:"type"`
+	Dashboard      string             `json:"dashboard"`
+	Tab            string             `json:"tab"`
+	PreviousStatus string             `json:"previous_status,omitempty"`
+	Summary        *client.TabSummary `json:"summary,omitempty"`
+	Time           time.Time          `json:"time"`
+}
+
+// streamFilter maps dashboards to the tabs a client wants; a nil tab set means all tabs
+type streamFilter map[string]map[string]bool
+
+func (f streamFilter) matches(dashboard, tab string) bool {
+	tabs, ok := f[dashboard]
+	if !ok {
+		return false
+	}
+	return tabs == nil || tabs[tab]
+}
+
+// add merges a dashboard/tab selection into the filter
+func (f streamFilter) add(dashboard, tab string) {
+	tabs, ok := f[dashboard]
+	if tab == "" {
+		f[dashboard] = nil
+		return
+	}
+	if ok && tabs == nil {
+		return
+	}
+	if tabs == nil {
+		tabs = make(map[string]bool)
+		f[dashboard] = tabs
+	}
+	tabs[tab] = true
+}
+
+// streamClient is a connected SSE client
+type streamClient struct {
+	filter streamFilter
+	events chan StreamEvent
+}
+
+// Hub polls tab summaries once for all stream clients and fans out changes
+type Hub struct {
+	api        StreamAPI
+	interval   time.Duration
+	heartbeat  time.Duration
+	bufferSize int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	nextID  uint64
+	buffer  []StreamEvent
+	state   map[string]map[string]client.TabSummary
+	clients map[*streamClient]struct{}
+	wake    chan struct{}
+
+	clientsGauge *metrics.Vec
+	eventsTotal  *metrics.Vec
+	pollErrors   *metrics.Vec
+}
+
+// StreamOption is a functional option for Hub
+type StreamOption func(*Hub)
+
+// WithStreamInterval sets how often subscribed dashboards are polled
+func WithStreamInterval(d time.Duration) StreamOption {
+	return func(h *Hub) {
+		h.interval = d
+	}
+}
+
+// WithStreamHeartbeat sets how often idle clients receive a keep-alive comment
+func WithStreamHeartbeat(d time.Duration) StreamOption {
+	return func(h *Hub) {
+		h.heartbeat = d
+	}
+}
+
+// WithStreamBufferSize sets how many past events are kept for Last-Event-ID resume
+func WithStreamBufferSize(n int) StreamOption {
+	return func(h *Hub) {
+		h.bufferSize = n
+	}
+}
+
+// WithStreamRegistry records stream metrics in r
+func WithStreamRegistry(r *metrics.Registry) StreamOption {
+	return func(h *Hub) {
+		h.clientsGauge = r.NewGauge("testgrid_stream_clients", "Connected stream clients.")
+		h.eventsTotal = r.NewCounter("testgrid_stream_events_total", "Stream events emitted by type.", "type")
+		h.pollErrors = r.NewCounter("testgrid_stream_poll_errors_total", "Stream poller fetches that failed.")
+	}
+}
+
+// NewHub creates a stream hub; call Run to start polling
+func NewHub(api StreamAPI, opts ...StreamOption) *Hub {
+	h := &Hub{
+		api:        api,
+		interval:   DefaultStreamInterval,
+		heartbeat:  DefaultStreamHeartbeat,
+		bufferSize: DefaultStreamBufferSize,
+		now:        time.Now,
+		state:      make(map[string]map[string]client.TabSummary),
+		clients:    make(map[*streamClient]struct{}),
+		wake:       make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.clientsGauge == nil {
+		WithStreamRegistry(metrics.NewRegistry())(h)
+	}
+	return h
+}
+
+// Run polls subscribed dashboards until ctx is cancelled
+//
+// Only dashboards with at least one connected client are polled. A newly
+// connected client triggers an immediate poll.
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.wake:
+		}
+	}
+}
+
+// Poll fetches every subscribed dashboard once and broadcasts changes
+func (h *Hub) Poll(ctx context.Context) {
+	wanted := h.wanted()
+
+	for dashboard, tabs := range wanted {
+		summaries, full, err := h.fetch(ctx, dashboard, tabs)
+		if err != nil {
+			h.pollErrors.Inc()
+			continue
+		}
+		h.apply(dashboard, summaries, full)
+	}
+
+	// Forget dashboards nobody is listening to so snapshots are never stale
+	h.mu.Lock()
+	for dashboard := range h.state {
+		if _, ok := wanted[dashboard]; !ok {
+			delete(h.state, dashboard)
+		}
+	}
+	h.mu.Unlock()
+}
+
+// wanted returns the union of all client filters
+func (h *Hub) wanted() streamFilter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	union := make(streamFilter)
+	for c := range h.clients {
+		for dashboard, tabs := range c.filter {
+			if tabs == nil {
+				union.add(dashboard, "")
+				continue
+			}
+			for tab := range tabs {
+				union.add(dashboard, tab)
+			}
+		}
+	}
+	return union
+}
+
+// fetch lists all tabs of a dashboard, or only the requested ones
+func (h *Hub) fetch(ctx context.Context, dashboard string, tabs map[string]bool) ([]client.TabSummary, bool, error) {
+	if tabs == nil {
+		resp, err := h.api.ListTabSummaries(ctx, dashboard)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.TabSummaries, true, nil
+	}
+
+	summaries := make([]client.TabSummary, 0, len(tabs))
+	for tab := range tabs {
+		resp, err := h.api.GetTabSummary(ctx, dashboard, tab)
+		if err != nil {
+			return nil, false, err
+		}
+		summary := resp.TabSummary
+		if summary.TabName == "" {
+			summary.TabName = tab
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, false, nil
+}
+
+// apply diffs fetched summaries against known state and broadcasts the changes
+//
+// When full is true the summaries are the complete tab list, so tabs missing
+// from it are reported as removed.
+func (h *Hub) apply(dashboard string, summaries []client.TabSummary, full bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.state[dashboard]
+	if prev == nil {
+		prev = make(map[string]client.TabSummary)
+	}
+	next := make(map[string]client.TabSummary, len(prev))
+	if !full {
+		for k, v := range prev {
+			next[k] = v
+		}
+	}
+
+	now := h.now()
+	for _, s := range summaries {
+		next[s.TabName] = s
+		old, existed := prev[s.TabName]
+		switch {
+		case !existed:
+			h.broadcast(StreamEvent{Type: StreamEventAdded, Dashboard: dashboard, Tab: s.TabName, Summary: &s, Time: now})
+		case old.OverallStatus != s.OverallStatus:
+			h.broadcast(StreamEvent{Type: StreamEventStatus, Dashboard: dashboard, Tab: s.TabName, PreviousStatus: old.OverallStatus, Summary: &s, Time: now})
+		case old != s:
+			h.broadcast(StreamEvent{Type: StreamEventUpdate, Dashboard: dashboard, Tab: s.TabName, Summary: &s, Time: now})
+		}
+	}
+
+	if full {
+		for tab, old := range prev {
+			if _, ok := next[tab]; !ok {
+				h.broadcast(StreamEvent{Type: StreamEventRemoved, Dashboard: dashboard, Tab: tab, PreviousStatus: old.OverallStatus, Time: now})
+			}
+		}
+	}
+
+	h.state[dashboard] = next
+}
+
+// broadcast assigns an ID, buffers the event and queues it for matching clients; h.mu must be held
+func (h *Hub) broadcast(e StreamEvent) {
+	h.nextID++
+	e.ID = h.nextID
+	h.buffer = append(h.buffer, e)
+	if len(h.buffer) > h.bufferSize {
+		h.buffer = h.buffer[len(h.buffer)-h.bufferSize:]
+	}
+	h.eventsTotal.Inc(e.Type)
+
+	for c := range h.clients {
+		if !c.filter.matches(e.Dashboard, e.Tab) {
+			continue
+		}
+		select {
+		case c.events <- e:
+		default:
+			// Drop clients that cannot keep up; they resume via Last-Event-ID
+			h.removeClient(c)
+		}
+	}
+}
+
+// removeClient unregisters a client and closes its queue; h.mu must be held
+func (h *Hub) removeClient(c *streamClient) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	close(c.events)
+	h.clientsGauge.Set(float64(len(h.clients)))
+}
+
+// subscribe registers a client and returns the events it missed
+//
+// If lastID is still covered by the buffer the missed events are replayed,
+// otherwise the client receives a snapshot of the current state.
+func (h *Hub) subscribe(filter streamFilter, lastID uint64, resume bool) (*streamClient, []StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := &streamClient{filter: filter, events: make(chan StreamEvent, clientQueueSize)}
+	h.clients[c] = struct{}{}
+	h.clientsGauge.Set(float64(len(h.clients)))
+
+	var backlog []StreamEvent
+	if resume && lastID <= h.nextID && (lastID == h.nextID || len(h.buffer) > 0 && h.buffer[0].ID <= lastID+1) {
+		for _, e := range h.buffer {
+			if e.ID > lastID && filter.matches(e.Dashboard, e.Tab) {
+				backlog = append(backlog, e)
+			}
+		}
+		return c, backlog
+	}
+
+	now := h.now()
+	for dashboard, tabs := range h.state {
+		for tab, s := range tabs {
+			if filter.matches(dashboard, tab) {
+				backlog = append(backlog, StreamEvent{Type: StreamEventSnapshot, Dashboard: dashboard, Tab: tab, Summary: &s, Time: now})
+			}
+		}
+	}
+
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+	return c, backlog
+}
+
+func (h *Hub) unsubscribe(c *streamClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeClient(c)
+}
+
+// parseStreamFilter reads dashboard, tab and target query parameters
+//
+// dashboard and tab may be repeated; tabs apply to every given dashboard.
+// target takes dashboard or dashboard/tab and may be repeated to mix both.
+func parseStreamFilter(r *http.Request) (streamFilter, error) {
+	q := r.URL.Query()
+	filter := make(streamFilter)
+
+	for _, dashboard := range q["dashboard"] {
+		if len(q["tab"]) == 0 {
+			filter.add(dashboard, "")
+		}
+		for _, tab := range q["tab"] {
+			filter.add(dashboard, tab)
+		}
+	}
+	for _, spec := range q["target"] {
+		t, err := watch.ParseTarget(spec)
+		if err != nil {
+			return nil, err
+		}
+		filter.add(t.Dashboard, t.Tab)
+	}
+
+	if len(filter) == 0 {
+		return nil, fmt.Errorf("at least one dashboard or target parameter is required")
+	}
+	return filter, nil
+}
+
+// ServeHTTP streams matching events to the client as Server-Sent Events
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseStreamFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	lastID, parseErr := strconv.ParseUint(lastEventID, 10, 64)
+	resume := lastEventID != "" && parseErr == nil
+
+	c, backlog := h.subscribe(filter, lastID, resume)
+	defer h.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	for _, e := range backlog {
+		writeStreamEvent(w, e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-c.events:
+			if !ok {
+				return
+			}
+			writeStreamEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, e StreamEvent) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if e.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", e.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// fakeStreamAPI serves mutable tab summaries
+type fakeStreamAPI struct {
+	mu        sync.Mutex
+	summaries map[string][]client.TabSummary
+}
+
+func (f *fakeStreamAPI) set(dashboard string, summaries ...client.TabSummary) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.summaries[dashboard] = summaries
+}
+
+func (f *fakeStreamAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &client.TabSummariesResponse{TabSummaries: append([]client.TabSummary(nil), f.summaries[dashboard]...)}, nil
+}
+
+func (f *fakeStreamAPI) GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.summaries[dashboard] {
+		if s.TabName == tab {
+			return &client.TabSummaryResponse{TabSummary: s}, nil
+		}
+	}
+	return &client.TabSummaryResponse{}, nil
+}
+
+// sseReader reads events from a text/event-stream body
+type sseReader struct {
+	sc *bufio.Scanner
+}
+
+func (r *sseReader) next(t *testing.T) (string, StreamEvent) {
+	t.Helper()
+	var typ, data string
+	for r.sc.Scan() {
+		line := r.sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			typ = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && typ != "":
+			var e StreamEvent
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatalf("invalid event data %q: %v", data, err)
+			}
+			return typ, e
+		}
+	}
+	t.Fatalf("stream ended: %v", r.sc.Err())
+	return "", StreamEvent{}
+}
+
+func connect(t *testing.T, url string, headers map[string]string) *sseReader {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	return &sseReader{sc: bufio.NewScanner(resp.Body)}
+}
+
+func TestStreamEventsAndResume(t *testing.T) {
+	api := &fakeStreamAPI{summaries: map[string][]client.TabSummary{}}
+	api.set("dash", client.TabSummary{TabName: "a", OverallStatus: "PASSING"}, client.TabSummary{TabName: "b", OverallStatus: "PASSING"})
+	hub := NewHub(api, WithStreamHeartbeat(time.Hour))
+	ts := httptest.NewServer(hub)
+	t.Cleanup(ts.Close)
+
+	all := connect(t, ts.URL+"?dashboard=dash", nil)
+	onlyB := connect(t, ts.URL+"?target=dash/b", nil)
+
+	hub.Poll(context.Background())
+	typ1, e1 := all.next(t)
+	typ2, _ := all.next(t)
+	if typ1 != StreamEventAdded || typ2 != StreamEventAdded {
+		t.Fatalf("expected two added events, got %s and %s", typ1, typ2)
+	}
+	if _, e := onlyB.next(t); e.Tab != "b" {
+		t.Errorf("expected filtered client to only see tab b, got %s", e.Tab)
+	}
+
+	api.set("dash", client.TabSummary{TabName: "a", OverallStatus: "FAILING"}, client.TabSummary{TabName: "b", OverallStatus: "PASSING"})
+	hub.Poll(context.Background())
+	typ, e := all.next(t)
+	if typ != StreamEventStatus || e.Tab != "a" || e.PreviousStatus != "PASSING" || e.Summary.OverallStatus != "FAILING" {
+		t.Errorf("unexpected status event: %s %+v", typ, e)
+	}
+
+	// Resuming after the first event replays everything since
+	resumed := connect(t, ts.URL+"?dashboard=dash", map[string]string{"Last-Event-ID": "1"})
+	_, r1 := resumed.next(t)
+	_, r2 := resumed.next(t)
+	if r1.ID != e1.ID+1 || r2.ID != e.ID {
+		t.Errorf("expected replay of events %d..%d, got %d and %d", e1.ID+1, e.ID, r1.ID, r2.ID)
+	}
+
+	// A fresh client gets a snapshot of the current state
+	fresh := connect(t, ts.URL+"?dashboard=dash&tab=a", nil)
+	typ, e = fresh.next(t)
+	if typ != StreamEventSnapshot || e.Tab != "a" || e.Summary.OverallStatus != "FAILING" {
+		t.Errorf("unexpected snapshot event: %s %+v", typ, e)
+	}
+}
+
+func TestStreamResumeWithoutBuffer(t *testing.T) {
+	api := &fakeStreamAPI{summaries: map[string][]client.TabSummary{}}
+	api.set("dash", client.TabSummary{TabName: "a", OverallStatus: "PASSING"}, client.TabSummary{TabName: "b", OverallStatus: "PASSING"})
+	hub := NewHub(api, WithStreamHeartbeat(time.Hour), WithStreamBufferSize(0))
+	first, _ := hub.subscribe(streamFilter{"dash": nil}, 0, false)
+	defer hub.unsubscribe(first)
+	hub.Poll(context.Background())
+
+	// Nothing is buffered, so a client that missed events gets a snapshot
+	c, backlog := hub.subscribe(streamFilter{"dash": nil}, 1, true)
+	defer hub.unsubscribe(c)
+	if len(backlog) != 2 || backlog[0].Type != StreamEventSnapshot {
+		t.Errorf("expected a snapshot of both tabs, got %+v", backlog)
+	}
+}
+
+func TestStreamRemovedTabs(t *testing.T) {
+	api := &fakeStreamAPI{summaries: map[string][]client.TabSummary{}}
+	api.set("dash", client.TabSummary{TabName: "a", OverallStatus: "PASSING"})
+	hub := NewHub(api)
+
+	c, _ := hub.subscribe(streamFilter{"dash": nil}, 0, false)
+	hub.Poll(context.Background())
+	api.set("dash")
+	hub.Poll(context.Background())
+
+	if e := <-c.events; e.Type != StreamEventAdded {
+		t.Errorf("expected added event, got %s", e.Type)
+	}
+	if e := <-c.events; e.Type != StreamEventRemoved || e.Tab != "a" {
+		t.Errorf("expected removed event for a, got %+v", e)
+	}
+}
+
+func TestStreamRequiresFilter(t *testing.T) {
+	hub := NewHub(&fakeStreamAPI{})
+	rec := httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stream", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestStreamFilter(t *testing.T) {
+	f := make(streamFilter)
+	f.add("d", "a")
+	f.add("d", "b")
+	if !f.matches("d", "a") || f.matches("d", "c") || f.matches("other", "a") {
+		t.Errorf("unexpected filter matches: %+v", f)
+	}
+
+	f.add("d", "")
+	f.add("d", "x")
+	if !f.matches("d", "c") {
+		t.Errorf("expected whole-dashboard selection to win, got %+v", f)
+	}
+}
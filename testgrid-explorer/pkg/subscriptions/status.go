@@ -0,0 +1,91 @@
+package subscriptions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/parallel"
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+)
+
+// DefaultConcurrency is the number of targets fetched at once by FetchStatuses
+const DefaultConcurrency = 8
+
+// API is the subset of the TestGrid client needed to resolve subscription status
+type API interface {
+	GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error)
+	GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// Status is the current state of a subscribed target
+type Status struct {
+	Subscription Subscription `json:"subscription"`
+	Status       string       `json:"status"`
+	Message      string       `json:"message,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// FetchStatuses fetches the current status of every subscription in parallel
+//
+// Results are returned in the same order as subs. Per-target errors are
+// recorded on the corresponding Status rather than aborting the whole fetch.
+func FetchStatuses(ctx context.Context, api API, subs []Subscription, concurrency int) []Status {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]Status, len(subs))
+	g := parallel.NewGroup(concurrency)
+	for i, s := range subs {
+		g.Go(func() {
+			st := Status{Subscription: s}
+			status, msg, err := fetchStatus(ctx, api, s)
+			if err != nil {
+				st.Status = "ERROR"
+				st.Error = err.Error()
+			} else {
+				st.Status = status
+				st.Message = msg
+			}
+			results[i] = st
+		})
+	}
+	g.Wait()
+	return results
+}
+
+func fetchStatus(ctx context.Context, api API, s Subscription) (string, string, error) {
+	switch s.Type {
+	case watch.TargetDashboard:
+		resp, err := api.GetDashboardSummary(ctx, s.DashboardName)
+		if err != nil {
+			return "", "", err
+		}
+		return resp.DashboardSummary.OverallStatus, "", nil
+
+	case watch.TargetTab:
+		resp, err := api.GetTabSummary(ctx, s.DashboardName, s.TabName)
+		if err != nil {
+			return "", "", err
+		}
+		return resp.TabSummary.OverallStatus, resp.TabSummary.DetailedStatusMessage, nil
+
+	case watch.TargetTest:
+		resp, err := api.GetTabRows(ctx, s.DashboardName, s.TabName)
+		if err != nil {
+			return "", "", err
+		}
+		for _, r := range resp.Rows {
+			if r.Name == s.TestName {
+				status, msg := watch.TestStatus(r.Cells)
+				return status, msg, nil
+			}
+		}
+		return "", "", fmt.Errorf("test %q not found", s.TestName)
+
+	default:
+		return "", "", fmt.Errorf("unknown subscription type %q", s.Type)
+	}
+}
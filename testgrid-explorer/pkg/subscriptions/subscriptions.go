@@ -0,0 +1,215 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+)
+
+// StorageVersion is the only storage schema version Pano understands
+const StorageVersion = 1
+
+// Subscription mirrors Pano's subscription schema
+type Subscription struct {
+	ID            string           `json:"id"`
+	CreatedAt     string           `json:"createdAt"`
+	Type          watch.TargetType `json:"type"`
+	DashboardName string           `json:"dashboardName"`
+	TabName       string           `json:"tabName,omitempty"`
+	TestName      string           `json:"testName,omitempty"`
+}
+
+// NotificationSettings mirrors Pano's notification settings schema
+type NotificationSettings struct {
+	Enabled              bool   `json:"enabled"`
+	PollIntervalSeconds  int    `json:"pollIntervalSeconds"`
+	QuietHoursStart      string `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd        string `json:"quietHoursEnd,omitempty"`
+	BrowserNotifications bool   `json:"browserNotifications"`
+}
+
+// Storage is the file format shared with Pano's exportSubscriptions
+type Storage struct {
+	Version              int                   `json:"version"`
+	Subscriptions        []Subscription        `json:"subscriptions"`
+	ExportedAt           string                `json:"exportedAt,omitempty"`
+	NotificationSettings *NotificationSettings `json:"notificationSettings,omitempty"`
+}
+
+// GenerateID returns the Pano subscription ID for a target
+func GenerateID(t watch.Target) string {
+	switch t.Type {
+	case watch.TargetTest:
+		return watch.Key(t.Dashboard, t.Tab, t.Test)
+	case watch.TargetTab:
+		return watch.Key(t.Dashboard, t.Tab, "")
+	default:
+		return watch.Key(t.Dashboard, "", "")
+	}
+}
+
+// Target converts a subscription to a watch target
+func (s Subscription) Target() watch.Target {
+	return watch.Target{
+		Type:      s.Type,
+		Dashboard: s.DashboardName,
+		Tab:       s.TabName,
+		Test:      s.TestName,
+	}
+}
+
+// Validate checks a subscription against Pano's schema
+func (s Subscription) Validate() error {
+	if s.ID == "" {
+		return errors.New("missing id")
+	}
+	if s.DashboardName == "" {
+		return fmt.Errorf("%s: missing dashboardName", s.ID)
+	}
+	switch s.Type {
+	case watch.TargetDashboard:
+	case watch.TargetTab:
+		if s.TabName == "" {
+			return fmt.Errorf("%s: missing tabName", s.ID)
+		}
+	case watch.TargetTest:
+		if s.TabName == "" || s.TestName == "" {
+			return fmt.Errorf("%s: missing tabName or testName", s.ID)
+		}
+	default:
+		return fmt.Errorf("%s: invalid type %q", s.ID, s.Type)
+	}
+	return nil
+}
+
+// Parse decodes and validates a Pano subscriptions export
+func Parse(data []byte) (*Storage, error) {
+	var st Storage
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("decoding subscriptions: %w", err)
+	}
+	if st.Version != StorageVersion {
+		return nil, fmt.Errorf("unsupported subscriptions version %d", st.Version)
+	}
+	for _, s := range st.Subscriptions {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid subscription: %w", err)
+		}
+	}
+	if st.Subscriptions == nil {
+		st.Subscriptions = []Subscription{}
+	}
+	return &st, nil
+}
+
+// Load reads subscriptions from path, returning an empty set if the file does not exist
+func Load(path string) (*Storage, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Storage{Version: StorageVersion, Subscriptions: []Subscription{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading subscriptions: %w", err)
+	}
+	return Parse(data)
+}
+
+// Save writes subscriptions to path
+func (st *Storage) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating subscriptions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding subscriptions: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing subscriptions: %w", err)
+	}
+	return nil
+}
+
+// Add subscribes to a target, returning false if it was already subscribed
+func (st *Storage) Add(t watch.Target, now time.Time) (Subscription, bool) {
+	id := GenerateID(t)
+	for _, s := range st.Subscriptions {
+		if s.ID == id {
+			return s, false
+		}
+	}
+
+	s := Subscription{
+		ID:            id,
+		CreatedAt:     now.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		Type:          t.Type,
+		DashboardName: t.Dashboard,
+		TabName:       t.Tab,
+		TestName:      t.Test,
+	}
+	st.Subscriptions = append(st.Subscriptions, s)
+	return s, true
+}
+
+// Remove unsubscribes by ID, returning false if no subscription matched
+func (st *Storage) Remove(id string) bool {
+	for i, s := range st.Subscriptions {
+		if s.ID == id {
+			st.Subscriptions = append(st.Subscriptions[:i], st.Subscriptions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Merge adds subscriptions not already present, returning how many were added
+func (st *Storage) Merge(other *Storage) int {
+	existing := make(map[string]bool, len(st.Subscriptions))
+	for _, s := range st.Subscriptions {
+		existing[s.ID] = true
+	}
+
+	added := 0
+	for _, s := range other.Subscriptions {
+		if existing[s.ID] {
+			continue
+		}
+		st.Subscriptions = append(st.Subscriptions, s)
+		existing[s.ID] = true
+		added++
+	}
+	if st.NotificationSettings == nil && other.NotificationSettings != nil {
+		st.NotificationSettings = other.NotificationSettings
+	}
+	return added
+}
+
+// Export renders the subscriptions in Pano's export format
+func (st *Storage) Export(now time.Time) ([]byte, error) {
+	out := *st
+	out.Version = StorageVersion
+	out.ExportedAt = now.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// Targets returns the watch targets for every subscription
+func (st *Storage) Targets() []watch.Target {
+	targets := make([]watch.Target, 0, len(st.Subscriptions))
+	for _, s := range st.Subscriptions {
+		targets = append(targets, s.Target())
+	}
+	return targets
+}
+
+// DefaultPath returns the default location of the subscriptions file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "testgrid", "subscriptions.json")
+}
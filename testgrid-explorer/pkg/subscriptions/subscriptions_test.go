@@ -0,0 +1,179 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+	"github.com/sozercan/testgrid-explorer/pkg/watch"
+)
+
+// panoExport is a sample produced by Pano's exportSubscriptions
+const panoExport = `{
+  "version": 1,
+  "subscriptions": [
+    {
+      "type": "dashboard",
+      "dashboardName": "sig-release-master-blocking",
+      "id": "dashboard:sig-release-master-blocking",
+      "createdAt": "2026-01-28T18:16:55.000Z"
+    },
+    {
+      "type": "tab",
+      "dashboardName": "sig-release-master-blocking",
+      "tabName": "kind-master",
+      "id": "tab:sig-release-master-blocking:kind-master",
+      "createdAt": "2026-01-28T18:17:01.000Z"
+    },
+    {
+      "type": "test",
+      "dashboardName": "sig-release-master-blocking",
+      "tabName": "kind-master",
+      "testName": "Kubernetes e2e suite.[It] [sig-node] Pods",
+      "id": "test:sig-release-master-blocking:kind-master:Kubernetes e2e suite.[It] [sig-node] Pods",
+      "createdAt": "2026-01-28T18:17:09.000Z"
+    }
+  ],
+  "exportedAt": "2026-01-29T09:00:00.000Z"
+}`
+
+func TestParsePanoExport(t *testing.T) {
+	st, err := Parse([]byte(panoExport))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(st.Subscriptions) != 3 {
+		t.Fatalf("expected 3 subscriptions, got %d", len(st.Subscriptions))
+	}
+
+	test := st.Subscriptions[2]
+	if test.Type != watch.TargetTest || test.TestName != "Kubernetes e2e suite.[It] [sig-node] Pods" {
+		t.Errorf("unexpected test subscription: %+v", test)
+	}
+	if GenerateID(test.Target()) != test.ID {
+		t.Errorf("expected generated ID to match Pano's, got %q", GenerateID(test.Target()))
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"bad json", `{`},
+		{"wrong version", `{"version": 2, "subscriptions": []}`},
+		{"bad type", `{"version": 1, "subscriptions": [{"id": "x", "type": "group", "dashboardName": "d", "createdAt": ""}]}`},
+		{"missing tab", `{"version": 1, "subscriptions": [{"id": "tab:d:", "type": "tab", "dashboardName": "d", "createdAt": ""}]}`},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.data)); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestAddRemoveMerge(t *testing.T) {
+	st := &Storage{Version: StorageVersion}
+	now := time.Date(2026, 1, 28, 18, 0, 0, 0, time.UTC)
+
+	sub, added := st.Add(watch.Target{Type: watch.TargetTab, Dashboard: "d", Tab: "t"}, now)
+	if !added || sub.ID != "tab:d:t" || sub.CreatedAt != "2026-01-28T18:00:00.000Z" {
+		t.Errorf("unexpected subscription: %+v (added=%v)", sub, added)
+	}
+	if _, added := st.Add(watch.Target{Type: watch.TargetTab, Dashboard: "d", Tab: "t"}, now); added {
+		t.Error("expected duplicate add to be ignored")
+	}
+
+	imported, err := Parse([]byte(panoExport))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := st.Merge(imported); n != 3 {
+		t.Errorf("expected 3 merged subscriptions, got %d", n)
+	}
+	if n := st.Merge(imported); n != 0 {
+		t.Errorf("expected re-merge to add nothing, got %d", n)
+	}
+
+	if !st.Remove("tab:d:t") {
+		t.Error("expected remove to succeed")
+	}
+	if st.Remove("tab:d:t") {
+		t.Error("expected second remove to fail")
+	}
+	if len(st.Subscriptions) != 3 {
+		t.Errorf("expected 3 subscriptions left, got %d", len(st.Subscriptions))
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subs.json")
+
+	empty, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading missing file: %v", err)
+	}
+	if len(empty.Subscriptions) != 0 {
+		t.Errorf("expected no subscriptions, got %d", len(empty.Subscriptions))
+	}
+
+	st, _ := Parse([]byte(panoExport))
+	if err := st.Save(path); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if len(loaded.Subscriptions) != 3 {
+		t.Errorf("expected 3 subscriptions, got %d", len(loaded.Subscriptions))
+	}
+
+	data, err := loaded.Export(time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error exporting: %v", err)
+	}
+	if _, err := Parse(data); err != nil {
+		t.Errorf("expected export to parse, got %v", err)
+	}
+}
+
+// fakeAPI serves canned responses keyed by dashboard/tab
+type fakeAPI struct{}
+
+func (fakeAPI) GetDashboardSummary(ctx context.Context, dashboard string) (*client.DashboardSummaryResponse, error) {
+	return &client.DashboardSummaryResponse{DashboardSummary: client.DashboardSummary{Name: dashboard, OverallStatus: "FLAKY"}}, nil
+}
+
+func (fakeAPI) GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error) {
+	return nil, errors.New("API error: status 404: not found")
+}
+
+func (fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	return &client.RowsResponse{Rows: []client.Row{
+		{Name: "Kubernetes e2e suite.[It] [sig-node] Pods", Cells: []client.Cell{{}, {Result: client.CellResultFail, Message: "timeout"}}},
+	}}, nil
+}
+
+func TestFetchStatuses(t *testing.T) {
+	st, _ := Parse([]byte(panoExport))
+	statuses := FetchStatuses(context.Background(), fakeAPI{}, st.Subscriptions, 2)
+
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+	if statuses[0].Status != "FLAKY" {
+		t.Errorf("expected dashboard status FLAKY, got %q", statuses[0].Status)
+	}
+	if statuses[1].Status != "ERROR" || statuses[1].Error == "" {
+		t.Errorf("expected tab status ERROR with message, got %+v", statuses[1])
+	}
+	if statuses[2].Status != "FAILING" || statuses[2].Message != "timeout" {
+		t.Errorf("expected test status FAILING with message, got %+v", statuses[2])
+	}
+}
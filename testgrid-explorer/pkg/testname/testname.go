@@ -0,0 +1,194 @@
+package testname
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kinds of Ginkgo nodes that appear in test names
+var kinds = []string{
+	"It",
+	"BeforeSuite", "AfterSuite",
+	"SynchronizedBeforeSuite", "SynchronizedAfterSuite",
+	"ReportBeforeSuite", "ReportAfterSuite",
+	"DeferCleanup",
+}
+
+// NoSIG is the SIG of tests without a [sig-*] tag
+const NoSIG = "none"
+
+// KnownSIGs are the Kubernetes SIGs, used to tell the SIG in a dashboard
+// name such as "sig-api-machinery-gce" from the rest of the name
+var KnownSIGs = []string{
+	"api-machinery", "apps", "architecture", "auth", "autoscaling", "cli",
+	"cloud-provider", "cluster-lifecycle", "contributor-experience", "docs",
+	"etcd", "instrumentation", "k8s-infra", "multicluster", "network", "node",
+	"release", "scalability", "scheduling", "security", "storage", "testing",
+	"ui", "usability", "windows",
+}
+
+// Name is a parsed test name such as
+//
+//	Kubernetes e2e suite.[It] [sig-node] Pods should run [NodeConformance] [Conformance]
+type Name struct {
+	Raw string `json:"raw"`
+	// Suite is the part before the first ".[", e.g. "Kubernetes e2e suite"
+	Suite string `json:"suite,omitempty"`
+	// Kind is the Ginkgo node, e.g. "It" or "BeforeSuite"
+	Kind string `json:"kind,omitempty"`
+	// SIG is the owning SIG without the "sig-" prefix, e.g. "storage"
+	SIG string `json:"sig,omitempty"`
+	// Features and FeatureGates come from [Feature:X] and [FeatureGate:X] tags
+	Features     []string `json:"features,omitempty"`
+	FeatureGates []string `json:"feature_gates,omitempty"`
+	// Tags are the contents of every other bracket, e.g. "Serial" or "Driver: csi-hostpath"
+	Tags []string `json:"tags,omitempty"`
+	// Description is the name without suite, kind, SIG and tags
+	Description string `json:"description"`
+}
+
+// Parse extracts the metadata encoded in a test name
+//
+// Names that do not follow the e2e conventions parse to a Name with only
+// Raw and Description set.
+func Parse(raw string) Name {
+	n := Name{Raw: raw}
+	rest := raw
+
+	if i := strings.Index(rest, ".["); i > 0 && !strings.Contains(rest[:i], "[") {
+		n.Suite = rest[:i]
+		rest = rest[i+1:]
+	}
+
+	var desc []string
+	for rest != "" {
+		open := strings.IndexByte(rest, '[')
+		if open < 0 {
+			desc = append(desc, rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], ']')
+		if end < 0 {
+			desc = append(desc, rest)
+			break
+		}
+		desc = append(desc, rest[:open])
+		tag := strings.TrimSpace(rest[open+1 : open+end])
+		rest = rest[open+end+1:]
+
+		switch {
+		case n.Kind == "" && n.SIG == "" && len(n.Tags) == 0 && slices.Contains(kinds, tag):
+			n.Kind = tag
+		case n.SIG == "" && strings.HasPrefix(tag, "sig-"):
+			n.SIG = strings.TrimPrefix(tag, "sig-")
+		case strings.HasPrefix(tag, "FeatureGate:"):
+			n.FeatureGates = append(n.FeatureGates, strings.TrimSpace(strings.TrimPrefix(tag, "FeatureGate:")))
+		case strings.HasPrefix(tag, "Feature:"):
+			n.Features = append(n.Features, strings.TrimSpace(strings.TrimPrefix(tag, "Feature:")))
+		case tag != "":
+			n.Tags = append(n.Tags, tag)
+		}
+	}
+	n.Description = strings.Join(strings.Fields(strings.Join(desc, " ")), " ")
+	return n
+}
+
+// DashboardSIG returns the SIG a dashboard name starts with, e.g. "node"
+// for "sig-node-release-blocking", or "" if it does not name a known SIG
+func DashboardSIG(dashboard string) string {
+	rest, ok := strings.CutPrefix(strings.ToLower(dashboard), "sig-")
+	if !ok {
+		return ""
+	}
+	best := ""
+	for _, sig := range KnownSIGs {
+		if (rest == sig || strings.HasPrefix(rest, sig+"-")) && len(sig) > len(best) {
+			best = sig
+		}
+	}
+	return best
+}
+
+// SIGOrNone returns the SIG, or NoSIG for untagged tests
+func (n Name) SIGOrNone() string {
+	if n.SIG == "" {
+		return NoSIG
+	}
+	return n.SIG
+}
+
+// HasTag reports whether the name carries tag, ignoring case
+//
+// "Feature:X" and "FeatureGate:X" match feature tags, and a key such as
+// "Feature" or "Driver" matches any tag of that key.
+func (n Name) HasTag(tag string) bool {
+	tag = strings.TrimSpace(tag)
+	matches := func(key string, values []string) bool {
+		for _, v := range values {
+			if strings.EqualFold(tag, key) || strings.EqualFold(tag, key+":"+v) || strings.EqualFold(tag, key+": "+v) {
+				return true
+			}
+		}
+		return false
+	}
+	if matches("Feature", n.Features) || matches("FeatureGate", n.FeatureGates) {
+		return true
+	}
+	for _, t := range n.Tags {
+		key, _, _ := strings.Cut(t, ":")
+		if strings.EqualFold(t, tag) || strings.EqualFold(strings.TrimSpace(key), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter selects tests by SIG and tags
+type Filter struct {
+	// SIGs keeps tests owned by any of these SIGs, with or without "sig-"
+	SIGs []string
+	// Tags keeps tests carrying all of these tags
+	Tags []string
+	// ExcludeTags drops tests carrying any of these tags
+	ExcludeTags []string
+}
+
+// Empty reports whether f keeps every test
+func (f Filter) Empty() bool {
+	return len(f.SIGs) == 0 && len(f.Tags) == 0 && len(f.ExcludeTags) == 0
+}
+
+// Match reports whether the test named raw passes f
+func (f Filter) Match(raw string) bool {
+	if f.Empty() {
+		return true
+	}
+	n := Parse(raw)
+	if !f.MatchSIG(n.SIGOrNone()) {
+		return false
+	}
+	return f.MatchTags(n)
+}
+
+// MatchSIG reports whether sig is selected by f.SIGs, which are compared
+// without their "sig-" prefix
+func (f Filter) MatchSIG(sig string) bool {
+	return len(f.SIGs) == 0 || slices.ContainsFunc(f.SIGs, func(s string) bool {
+		return strings.EqualFold(strings.TrimPrefix(strings.ToLower(s), "sig-"), sig)
+	})
+}
+
+// MatchTags reports whether n carries all of f.Tags and none of f.ExcludeTags
+func (f Filter) MatchTags(n Name) bool {
+	for _, t := range f.Tags {
+		if !n.HasTag(t) {
+			return false
+		}
+	}
+	for _, t := range f.ExcludeTags {
+		if n.HasTag(t) {
+			return false
+		}
+	}
+	return true
+}
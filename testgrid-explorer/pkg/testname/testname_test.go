@@ -0,0 +1,97 @@
+package testname
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Name
+	}{
+		{
+			raw: "Kubernetes e2e suite.[It] [sig-api-machinery] Foo should work [Serial] [Conformance]",
+			want: Name{Suite: "Kubernetes e2e suite", Kind: "It", SIG: "api-machinery",
+				Tags: []string{"Serial", "Conformance"}, Description: "Foo should work"},
+		},
+		{
+			raw:  "Kubernetes e2e suite.[BeforeSuite]",
+			want: Name{Suite: "Kubernetes e2e suite", Kind: "BeforeSuite"},
+		},
+		{
+			raw: "[sig-storage] CSI Volumes [Driver: csi-hostpath] [Testpattern: Dynamic PV] should mount [Feature:VolumeSnapshot] [FeatureGate:CSIMigration] [Beta]",
+			want: Name{SIG: "storage", Features: []string{"VolumeSnapshot"}, FeatureGates: []string{"CSIMigration"},
+				Tags: []string{"Driver: csi-hostpath", "Testpattern: Dynamic PV", "Beta"}, Description: "CSI Volumes should mount"},
+		},
+		{
+			raw:  "Overall",
+			want: Name{Description: "Overall"},
+		},
+		{
+			raw:  "ci-kubernetes-unit.k8s.io/kubernetes/pkg/api [broken",
+			want: Name{Description: "ci-kubernetes-unit.k8s.io/kubernetes/pkg/api [broken"},
+		},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.raw)
+		tt.want.Raw = tt.raw
+		if got.Suite != tt.want.Suite || got.Kind != tt.want.Kind || got.SIG != tt.want.SIG ||
+			got.Description != tt.want.Description ||
+			!slices.Equal(got.Tags, tt.want.Tags) ||
+			!slices.Equal(got.Features, tt.want.Features) ||
+			!slices.Equal(got.FeatureGates, tt.want.FeatureGates) {
+			t.Errorf("Parse(%q):\n got  %+v\n want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	const (
+		conformance = "Kubernetes e2e suite.[It] [sig-node] Pods should run [NodeConformance] [Conformance]"
+		flaky       = "Kubernetes e2e suite.[It] [sig-storage] CSI [Driver: csi-hostpath] should mount [Flaky]"
+		feature     = "Kubernetes e2e suite.[It] [sig-storage] Snapshots [Feature:VolumeSnapshot]"
+		untagged    = "Overall"
+	)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"empty", Filter{}, []string{conformance, flaky, feature, untagged}},
+		{"sig", Filter{SIGs: []string{"sig-Storage"}}, []string{flaky, feature}},
+		{"no sig", Filter{SIGs: []string{NoSIG}}, []string{untagged}},
+		{"tag", Filter{Tags: []string{"conformance"}}, []string{conformance}},
+		{"feature", Filter{Tags: []string{"Feature:VolumeSnapshot"}}, []string{feature}},
+		{"tag key", Filter{Tags: []string{"Driver"}}, []string{flaky}},
+		{"exclude", Filter{SIGs: []string{"storage"}, ExcludeTags: []string{"Flaky"}}, []string{feature}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, raw := range []string{conformance, flaky, feature, untagged} {
+			if tt.filter.Match(raw) {
+				got = append(got, raw)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDashboardSIG(t *testing.T) {
+	tests := map[string]string{
+		"sig-node-release-blocking":     "node",
+		"sig-api-machinery-gce":         "api-machinery",
+		"sig-cluster-lifecycle-kubeadm": "cluster-lifecycle",
+		"sig-release":                   "release",
+		"sig-unknown-dashboard":         "",
+		"conformance-all":               "",
+		"SIG-Storage-Kubernetes-CSI":    "storage",
+	}
+	for dashboard, want := range tests {
+		if got := DashboardSIG(dashboard); got != want {
+			t.Errorf("DashboardSIG(%q) = %q, want %q", dashboard, got, want)
+		}
+	}
+}
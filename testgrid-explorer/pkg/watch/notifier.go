@@ -0,0 +1,129 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// Notifier delivers status change events
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// StdoutNotifier writes one line per event, as text or JSON
+type StdoutNotifier struct {
+	w      io.Writer
+	asJSON bool
+}
+
+// NewStdoutNotifier creates a notifier that writes events to w
+func NewStdoutNotifier(w io.Writer, asJSON bool) *StdoutNotifier {
+	return &StdoutNotifier{w: w, asJSON: asJSON}
+}
+
+// Name implements Notifier
+func (n *StdoutNotifier) Name() string { return "stdout" }
+
+// Notify implements Notifier
+func (n *StdoutNotifier) Notify(ctx context.Context, event Event) error {
+	if n.asJSON {
+		return json.NewEncoder(n.w).Encode(event)
+	}
+	_, err := fmt.Fprintf(n.w, "%s [%s] %s: %s -> %s\n",
+		event.Time.Format(time.RFC3339), event.Kind, event.Key, event.Previous, event.Current)
+	return err
+}
+
+// WebhookNotifier POSTs each event as JSON to a URL
+type WebhookNotifier struct {
+	url        string
+	httpClient client.HTTPClient
+}
+
+// NewWebhookNotifier creates a notifier that POSTs events to url
+func NewWebhookNotifier(url string, hc client.HTTPClient) *WebhookNotifier {
+	if hc == nil {
+		hc = &http.Client{Timeout: client.DefaultTimeout}
+	}
+	return &WebhookNotifier{url: url, httpClient: hc}
+}
+
+// Name implements Notifier
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// Notify implements Notifier
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook error: status %d: %s", resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// ExecNotifier runs a command for each event
+//
+// The event is passed as JSON on stdin and its main fields are exported as
+// TESTGRID_* environment variables.
+type ExecNotifier struct {
+	command string
+}
+
+// NewExecNotifier creates a notifier that runs command via sh -c
+func NewExecNotifier(command string) *ExecNotifier {
+	return &ExecNotifier{command: command}
+}
+
+// Name implements Notifier
+func (n *ExecNotifier) Name() string { return "exec" }
+
+// Notify implements Notifier
+func (n *ExecNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"TESTGRID_EVENT="+string(event.Kind),
+		"TESTGRID_KEY="+event.Key,
+		"TESTGRID_DASHBOARD="+event.Dashboard,
+		"TESTGRID_TAB="+event.Tab,
+		"TESTGRID_TEST="+event.Test,
+		"TESTGRID_PREVIOUS_STATUS="+event.Previous,
+		"TESTGRID_STATUS="+event.Current,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("running %q: %w: %s", n.command, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const stateVersion = 1
+
+// Observation is the last known status of a watched tab or test
+type Observation struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// State holds the last known status for every watched key
+type State struct {
+	Version  int                    `json:"version"`
+	Statuses map[string]Observation `json:"statuses"`
+}
+
+// NewState returns an empty state
+func NewState() *State {
+	return &State{
+		Version:  stateVersion,
+		Statuses: make(map[string]Observation),
+	}
+}
+
+// LoadState reads state from path, returning an empty state if the file does not exist
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state: %w", err)
+	}
+
+	s := NewState()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("decoding state %s: %w", path, err)
+	}
+	if s.Statuses == nil {
+		s.Statuses = make(map[string]Observation)
+	}
+	return s, nil
+}
+
+// Save writes state to path atomically
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing state: %w", err)
+	}
+	return nil
+}
+
+// DefaultStatePath returns the default location of the watch state file
+func DefaultStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "testgrid", "watch-state.json")
+}
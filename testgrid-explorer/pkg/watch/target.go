@@ -0,0 +1,69 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TargetType identifies what a watch target points at
+type TargetType string
+
+const (
+	TargetDashboard TargetType = "dashboard"
+	TargetTab       TargetType = "tab"
+	TargetTest      TargetType = "test"
+)
+
+// Target is a dashboard, tab or test to poll
+type Target struct {
+	Type      TargetType `json:"type"`
+	Dashboard string     `json:"dashboard"`
+	Tab       string     `json:"tab,omitempty"`
+	Test      string     `json:"test,omitempty"`
+}
+
+// ParseTarget parses a target spec of the form dashboard[/tab[/test]]
+//
+// Test names may themselves contain slashes, so everything after the
+// second separator is treated as the test name.
+func ParseTarget(spec string) (Target, error) {
+	parts := strings.SplitN(spec, "/", 3)
+	for _, p := range parts {
+		if p == "" {
+			return Target{}, fmt.Errorf("invalid target %q: expected dashboard[/tab[/test]]", spec)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return Target{Type: TargetDashboard, Dashboard: parts[0]}, nil
+	case 2:
+		return Target{Type: TargetTab, Dashboard: parts[0], Tab: parts[1]}, nil
+	default:
+		return Target{Type: TargetTest, Dashboard: parts[0], Tab: parts[1], Test: parts[2]}, nil
+	}
+}
+
+// Key returns the state key for a tab or test within a target
+func Key(dashboard, tab, test string) string {
+	switch {
+	case test != "":
+		return fmt.Sprintf("test:%s:%s:%s", dashboard, tab, test)
+	case tab != "":
+		return fmt.Sprintf("tab:%s:%s", dashboard, tab)
+	default:
+		return fmt.Sprintf("dashboard:%s", dashboard)
+	}
+}
+
+// String returns the target in dashboard[/tab[/test]] form
+func (t Target) String() string {
+	switch t.Type {
+	case TargetTest:
+		return t.Dashboard + "/" + t.Tab + "/" + t.Test
+	case TargetTab:
+		return t.Dashboard + "/" + t.Tab
+	default:
+		return t.Dashboard
+	}
+}
@@ -0,0 +1,276 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// EventKind classifies a status transition
+type EventKind string
+
+const (
+	EventFailing   EventKind = "failing"
+	EventRecovered EventKind = "recovered"
+	EventStale     EventKind = "stale"
+)
+
+// Event is emitted when a watched tab or test changes status
+type Event struct {
+	Kind      EventKind `json:"kind"`
+	Key       string    `json:"key"`
+	Dashboard string    `json:"dashboard"`
+	Tab       string    `json:"tab,omitempty"`
+	Test      string    `json:"test,omitempty"`
+	Previous  string    `json:"previous_status"`
+	Current   string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Transition reports whether a change from prev to cur should emit an event
+//
+// Only PASSING→FAILING style regressions, recoveries from FAILING or STALE,
+// and anything becoming STALE are reported; FLAKY noise is ignored.
+func Transition(prev, cur string) (EventKind, bool) {
+	if prev == "" || prev == cur {
+		return "", false
+	}
+	switch cur {
+	case "FAILING":
+		return EventFailing, true
+	case "STALE":
+		return EventStale, true
+	case "PASSING":
+		if prev == "FAILING" || prev == "STALE" {
+			return EventRecovered, true
+		}
+	}
+	return "", false
+}
+
+// API is the subset of the TestGrid client used by the watcher
+type API interface {
+	ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error)
+	GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error)
+	GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error)
+}
+
+// observed is a single status reading taken during a poll
+type observed struct {
+	key       string
+	dashboard string
+	tab       string
+	test      string
+	status    string
+	message   string
+}
+
+// Watcher polls targets and dispatches transition events to notifiers
+type Watcher struct {
+	api       API
+	targets   []Target
+	notifiers []Notifier
+	state     *State
+	statePath string
+	errOut    io.Writer
+	now       func() time.Time
+}
+
+// Option is a functional option for Watcher
+type Option func(*Watcher)
+
+// WithNotifiers sets the notifiers events are dispatched to
+func WithNotifiers(n ...Notifier) Option {
+	return func(w *Watcher) {
+		w.notifiers = append(w.notifiers, n...)
+	}
+}
+
+// WithState sets the initial state and the path it is persisted to
+//
+// An empty path keeps state in memory only.
+func WithState(s *State, path string) Option {
+	return func(w *Watcher) {
+		w.state = s
+		w.statePath = path
+	}
+}
+
+// WithErrorOutput sets where Run reports poll errors
+func WithErrorOutput(out io.Writer) Option {
+	return func(w *Watcher) {
+		w.errOut = out
+	}
+}
+
+// New creates a new Watcher
+func New(api API, targets []Target, opts ...Option) *Watcher {
+	w := &Watcher{
+		api:     api,
+		targets: targets,
+		state:   NewState(),
+		errOut:  io.Discard,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Poll fetches every target once, records the statuses and dispatches events
+//
+// Fetch and notifier errors are collected and returned together; a failing
+// target does not prevent the others from being checked. A transition is
+// only recorded once every notifier delivered its event, so an event that
+// failed to deliver fires again on the next poll, including to notifiers
+// that already received it.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	var errs []error
+	var events []Event
+	now := w.now()
+
+	for _, t := range w.targets {
+		obs, err := w.observe(ctx, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t, err))
+			continue
+		}
+
+		for _, o := range obs {
+			prev := w.state.Statuses[o.key]
+			if prev.Status == o.status {
+				continue
+			}
+			if kind, ok := Transition(prev.Status, o.status); ok {
+				e := Event{
+					Kind:      kind,
+					Key:       o.key,
+					Dashboard: o.dashboard,
+					Tab:       o.tab,
+					Test:      o.test,
+					Previous:  prev.Status,
+					Current:   o.status,
+					Message:   o.message,
+					Time:      now,
+				}
+				events = append(events, e)
+				if notifyErrs := w.notify(ctx, e); len(notifyErrs) > 0 {
+					errs = append(errs, notifyErrs...)
+					continue
+				}
+			}
+			w.state.Statuses[o.key] = Observation{Status: o.status, UpdatedAt: now}
+		}
+	}
+
+	if w.statePath != "" {
+		if err := w.state.Save(w.statePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// notify sends e to every notifier and returns their errors
+func (w *Watcher) notify(ctx context.Context, e Event) []error {
+	var errs []error
+	for _, n := range w.notifiers {
+		if err := n.Notify(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("notifier %s: %w", n.Name(), err))
+		}
+	}
+	return errs
+}
+
+// Run polls every interval until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Poll(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(w.errOut, "poll error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) observe(ctx context.Context, t Target) ([]observed, error) {
+	switch t.Type {
+	case TargetDashboard:
+		resp, err := w.api.ListTabSummaries(ctx, t.Dashboard)
+		if err != nil {
+			return nil, err
+		}
+		obs := make([]observed, 0, len(resp.TabSummaries))
+		for _, s := range resp.TabSummaries {
+			obs = append(obs, summaryObservation(t.Dashboard, s))
+		}
+		return obs, nil
+
+	case TargetTab:
+		resp, err := w.api.GetTabSummary(ctx, t.Dashboard, t.Tab)
+		if err != nil {
+			return nil, err
+		}
+		return []observed{summaryObservation(t.Dashboard, resp.TabSummary)}, nil
+
+	case TargetTest:
+		resp, err := w.api.GetTabRows(ctx, t.Dashboard, t.Tab)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Rows {
+			if r.Name == t.Test {
+				status, msg := TestStatus(r.Cells)
+				return []observed{{
+					key:       Key(t.Dashboard, t.Tab, t.Test),
+					dashboard: t.Dashboard,
+					tab:       t.Tab,
+					test:      t.Test,
+					status:    status,
+					message:   msg,
+				}}, nil
+			}
+		}
+		return nil, fmt.Errorf("test %q not found", t.Test)
+
+	default:
+		return nil, fmt.Errorf("unknown target type %q", t.Type)
+	}
+}
+
+func summaryObservation(dashboard string, s client.TabSummary) observed {
+	return observed{
+		key:       Key(dashboard, s.TabName, ""),
+		dashboard: dashboard,
+		tab:       s.TabName,
+		status:    s.OverallStatus,
+		message:   s.DetailedStatusMessage,
+	}
+}
+
+// TestStatus maps the most recent pass/fail cell to a tab-style status and message
+func TestStatus(cells []client.Cell) (string, string) {
+	for _, c := range cells {
+		switch c.Result {
+		case client.CellResultPass:
+			return "PASSING", c.Message
+		case client.CellResultFail:
+			return "FAILING", c.Message
+		}
+	}
+	return "UNKNOWN", ""
+}
@@ -0,0 +1,245 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sozercan/testgrid-explorer/pkg/client"
+)
+
+// fakeAPI serves canned summaries and rows
+type fakeAPI struct {
+	summaries map[string][]client.TabSummary
+	rows      map[string][]client.Row
+}
+
+func (f *fakeAPI) ListTabSummaries(ctx context.Context, dashboard string) (*client.TabSummariesResponse, error) {
+	return &client.TabSummariesResponse{TabSummaries: f.summaries[dashboard]}, nil
+}
+
+func (f *fakeAPI) GetTabSummary(ctx context.Context, dashboard, tab string) (*client.TabSummaryResponse, error) {
+	for _, s := range f.summaries[dashboard] {
+		if s.TabName == tab {
+			return &client.TabSummaryResponse{TabSummary: s}, nil
+		}
+	}
+	return &client.TabSummaryResponse{}, nil
+}
+
+func (f *fakeAPI) GetTabRows(ctx context.Context, dashboard, tab string) (*client.RowsResponse, error) {
+	return &client.RowsResponse{Rows: f.rows[dashboard+"/"+tab]}, nil
+}
+
+// recordingNotifier captures events for assertions
+type recordingNotifier struct {
+	events []Event
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(ctx context.Context, e Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+// failingNotifier fails to deliver the first failures events
+type failingNotifier struct {
+	failures  int
+	delivered []Event
+}
+
+func (f *failingNotifier) Name() string { return "failing" }
+
+func (f *failingNotifier) Notify(ctx context.Context, e Event) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("webhook returned 503")
+	}
+	f.delivered = append(f.delivered, e)
+	return nil
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Target
+		wantErr  bool
+	}{
+		{"sig-release-master-blocking", Target{Type: TargetDashboard, Dashboard: "sig-release-master-blocking"}, false},
+		{"dash/kind-master", Target{Type: TargetTab, Dashboard: "dash", Tab: "kind-master"}, false},
+		{"dash/tab/a/b test", Target{Type: TargetTest, Dashboard: "dash", Tab: "tab", Test: "a/b test"}, false},
+		{"dash//test", Target{}, true},
+		{"", Target{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTarget(%q) expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTarget(%q) unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseTarget(%q) = %+v, expected %+v", tt.spec, got, tt.expected)
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		prev, cur string
+		kind      EventKind
+		ok        bool
+	}{
+		{"", "FAILING", "", false},
+		{"PASSING", "PASSING", "", false},
+		{"PASSING", "FAILING", EventFailing, true},
+		{"FLAKY", "FAILING", EventFailing, true},
+		{"FAILING", "PASSING", EventRecovered, true},
+		{"STALE", "PASSING", EventRecovered, true},
+		{"FLAKY", "PASSING", "", false},
+		{"PASSING", "STALE", EventStale, true},
+		{"PASSING", "FLAKY", "", false},
+	}
+
+	for _, tt := range tests {
+		kind, ok := Transition(tt.prev, tt.cur)
+		if kind != tt.kind || ok != tt.ok {
+			t.Errorf("Transition(%q, %q) = (%q, %v), expected (%q, %v)", tt.prev, tt.cur, kind, ok, tt.kind, tt.ok)
+		}
+	}
+}
+
+func TestPollEmitsOnlyTransitions(t *testing.T) {
+	api := &fakeAPI{
+		summaries: map[string][]client.TabSummary{
+			"dash": {
+				{TabName: "a", OverallStatus: "PASSING"},
+				{TabName: "b", OverallStatus: "FLAKY"},
+			},
+		},
+		rows: map[string][]client.Row{
+			"dash/a": {{Name: "test-1", Cells: []client.Cell{{}, {Result: client.CellResultPass}}}},
+		},
+	}
+	rec := &recordingNotifier{}
+	targets := []Target{
+		{Type: TargetDashboard, Dashboard: "dash"},
+		{Type: TargetTest, Dashboard: "dash", Tab: "a", Test: "test-1"},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	w := New(api, targets, WithNotifiers(rec), WithState(NewState(), statePath))
+
+	// First poll only records state
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events on first poll, got %d", len(events))
+	}
+
+	api.summaries["dash"][0].OverallStatus = "FAILING"
+	api.summaries["dash"][1].OverallStatus = "PASSING"
+	api.rows["dash/a"][0].Cells = []client.Cell{{Result: client.CellResultFail, Message: "boom"}}
+
+	events, err = w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(events), events)
+	}
+	if events[0].Key != "tab:dash:a" || events[0].Kind != EventFailing {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Key != "test:dash:a:test-1" || events[1].Message != "boom" {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+	if len(rec.events) != 2 {
+		t.Errorf("expected notifier to receive 2 events, got %d", len(rec.events))
+	}
+
+	// A restarted watcher loads the persisted state and does not re-fire
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	restarted := New(api, targets, WithState(state, statePath))
+	events, err = restarted.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events after restart, got %+v", events)
+	}
+}
+
+func TestPollRetriesFailedNotifications(t *testing.T) {
+	api := &fakeAPI{summaries: map[string][]client.TabSummary{
+		"dash": {{TabName: "a", OverallStatus: "PASSING"}},
+	}}
+	n := &failingNotifier{failures: 1}
+	targets := []Target{{Type: TargetTab, Dashboard: "dash", Tab: "a"}}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	w := New(api, targets, WithNotifiers(n), WithState(NewState(), statePath))
+
+	if _, err := w.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.summaries["dash"][0].OverallStatus = "FAILING"
+
+	if _, err := w.Poll(context.Background()); err == nil || !strings.Contains(err.Error(), "notifier failing") {
+		t.Fatalf("expected notifier error, got %v", err)
+	}
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	if got := state.Statuses["tab:dash:a"].Status; got != "PASSING" {
+		t.Errorf("expected the undelivered transition not to be saved, got %q", got)
+	}
+
+	// The event fires again and is recorded once delivered
+	events, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || len(n.delivered) != 1 || n.delivered[0].Kind != EventFailing {
+		t.Fatalf("expected the failing event to be delivered on retry, got %+v", n.delivered)
+	}
+	if events, _ := w.Poll(context.Background()); len(events) != 0 {
+		t.Errorf("expected no events once delivered, got %+v", events)
+	}
+}
+
+func TestPollMissingTest(t *testing.T) {
+	api := &fakeAPI{}
+	w := New(api, []Target{{Type: TargetTest, Dashboard: "dash", Tab: "a", Test: "missing"}})
+
+	_, err := w.Poll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestStdoutNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewStdoutNotifier(&buf, false)
+	err := n.Notify(context.Background(), Event{Kind: EventFailing, Key: "tab:d:t", Previous: "PASSING", Current: "FAILING"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "[failing] tab:d:t: PASSING -> FAILING") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}